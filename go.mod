@@ -20,6 +20,8 @@ require (
 	github.com/gogo/protobuf v1.2.0 // indirect
 	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db // indirect
 	github.com/google/go-querystring v1.0.0 // indirect
+	github.com/gorilla/feeds v1.1.0
+	github.com/gorilla/websocket v1.4.0
 	github.com/labstack/echo v3.3.5+incompatible // indirect
 	github.com/labstack/gommon v0.2.8
 	github.com/mattn/go-colorable v0.0.9 // indirect
@@ -6,7 +6,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/toorop/gin-logrus"
 
-	"github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/model"
 )
 
 //Returns the Gin Engine
@@ -29,36 +29,91 @@ func API() *gin.Engine {
 
 	// the jwt middleware
 	authMiddleware := AuthMiddleware()
+	can := PermissionMiddleware
 
-	admin := r.Group("/v1/admin").Use(authMiddleware.MiddlewareFunc()).Use(UserMiddleware())
+	// only sessions can be refreshed, api tokens don't carry jwt claims
+	session := r.Group("/v1/admin")
+	session.Use(authMiddleware.MiddlewareFunc(), UserMiddleware())
 	{
-		admin.GET("/refresh_token", authMiddleware.RefreshHandler)
+		session.GET("/refresh_token", authMiddleware.RefreshHandler)
+	}
+
+	// reachable without two-factor authentication, so users can enable it when it's enforced
+	account := r.Group("/v1/admin")
+	account.Use(TokenMiddleware(authMiddleware), UserMiddleware())
+	{
+		account.POST(`/totp`, PostTOTPHandler)
+		account.POST(`/totp/enable`, PostTOTPEnableHandler)
+		account.POST(`/totp/disable`, PostTOTPDisableHandler)
+	}
 
+	admin := r.Group("/v1/admin")
+	admin.Use(TokenMiddleware(authMiddleware), UserMiddleware(), TwoFactorMiddleware())
+	{
 		admin.GET(`/tickers`, GetTickersHandler)
-		admin.GET(`/tickers/:tickerID`, GetTickerHandler)
 		admin.POST(`/tickers`, PostTickerHandler)
-		admin.PUT(`/tickers/:tickerID`, PutTickerHandler)
-		admin.PUT(`/tickers/:tickerID/twitter`, PutTickerTwitterHandler)
-		admin.DELETE(`/tickers/:tickerID`, DeleteTickerHandler)
-		admin.PUT(`/tickers/:tickerID/reset`, ResetTickerHandler)
-		admin.GET(`/tickers/:tickerID/users`, GetTickerUsersHandler)
-		admin.PUT(`/tickers/:tickerID/users`, PutTickerUsersHandler)
-		admin.DELETE(`/tickers/:tickerID/users/:userID`, DeleteTickerUserHandler)
-
-		admin.GET(`/tickers/:tickerID/messages`, GetMessagesHandler)
-		admin.GET(`/tickers/:tickerID/messages/:messageID`, GetMessageHandler)
-		admin.POST(`/tickers/:tickerID/messages`, PostMessageHandler)
-		admin.DELETE(`/tickers/:tickerID/messages/:messageID`, DeleteMessageHandler)
+
+		// loads the ticker and checks the access of the user
+		ticker := admin.Group(`/tickers/:tickerID`)
+		ticker.Use(TickerMiddleware())
+		{
+			ticker.GET(``, can(PermissionReadTicker), GetTickerHandler)
+			ticker.PUT(``, can(PermissionWriteTicker), PutTickerHandler)
+			ticker.PUT(`/twitter`, can(PermissionWriteTicker), PutTickerTwitterHandler)
+			ticker.PUT(`/mastodon`, can(PermissionWriteTicker), PutTickerMastodonHandler)
+			ticker.PUT(`/telegram`, can(PermissionWriteTicker), PutTickerTelegramHandler)
+			ticker.DELETE(``, DeleteTickerHandler)
+			ticker.PUT(`/reset`, ResetTickerHandler)
+			ticker.GET(`/users`, can(PermissionReadTicker), GetTickerUsersHandler)
+			ticker.PUT(`/users`, can(PermissionManageUsers), PutTickerUsersHandler)
+			ticker.DELETE(`/users/:userID`, can(PermissionManageUsers), DeleteTickerUserHandler)
+			ticker.GET(`/webhooks`, can(PermissionWriteTicker), GetWebhooksHandler)
+			ticker.POST(`/webhooks`, can(PermissionWriteTicker), PostWebhookHandler)
+			ticker.DELETE(`/webhooks/:webhookID`, can(PermissionWriteTicker), DeleteWebhookHandler)
+			ticker.GET(`/webhooks/:webhookID/deliveries`, can(PermissionWriteTicker), GetWebhookDeliveriesHandler)
+
+			ticker.GET(`/messages`, can(PermissionReadMessages), GetMessagesHandler)
+			ticker.POST(`/messages`, can(PermissionCreateMessages), PostMessageHandler)
+
+			ticker.POST(`/uploads`, can(PermissionCreateMessages), PostUploadHandler)
+
+			// loads the message and ensures that it belongs to the ticker
+			message := ticker.Group(`/messages/:messageID`)
+			message.Use(MessageMiddleware())
+			{
+				message.GET(``, can(PermissionReadMessages), GetMessageHandler)
+				message.PUT(``, can(PermissionEditMessages), PutMessageHandler)
+				message.DELETE(``, can(PermissionEditMessages), DeleteMessageHandler)
+				message.GET(`/revisions`, can(PermissionReadMessages), GetMessageRevisionsHandler)
+				message.POST(`/retry`, can(PermissionEditMessages), RetryMessageHandler)
+				message.POST(`/approve`, can(PermissionApproveMessages), ApproveMessageHandler)
+				message.POST(`/reject`, can(PermissionApproveMessages), RejectMessageHandler)
+			}
+		}
 
 		admin.GET(`/users`, GetUsersHandler)
 		admin.GET(`/users/:userID`, GetUserHandler)
 		admin.POST(`/users`, PostUserHandler)
 		admin.PUT(`/users/:userID`, PutUserHandler)
 		admin.DELETE(`/users/:userID`, DeleteUserHandler)
+		admin.GET(`/users/:userID/tokens`, GetAPITokensHandler)
+		admin.POST(`/users/:userID/tokens`, PostAPITokenHandler)
+		admin.DELETE(`/users/:userID/tokens/:tokenID`, DeleteAPITokenHandler)
+		admin.DELETE(`/users/:userID/totp`, DeleteUserTOTPHandler)
 
 		admin.GET(`/settings/:name`, GetSettingHandler)
 		admin.PUT(`/settings/inactive_settings`, PutInactiveSettingsHandler)
 		admin.PUT(`/settings/refresh_interval`, PutRefreshIntervalHandler)
+		admin.PUT(`/settings/enforce_two_factor`, PutEnforceTwoFactorHandler)
+	}
+
+	// browsers can't set the authorization header for websocket connections
+	socketMiddleware := AuthMiddleware()
+	socketMiddleware.TokenLookup = "header:Authorization,query:token"
+
+	socket := r.Group("/v1/admin").Use(TokenMiddleware(socketMiddleware)).Use(UserMiddleware()).Use(TwoFactorMiddleware())
+	{
+		socket.GET(`/tickers/:tickerID/ws`, TickerMiddleware(), GetTickerSocketHandler)
 	}
 
 	public := r.Group("/v1").Use()
@@ -69,14 +124,28 @@ func API() *gin.Engine {
 
 		public.GET(`/init`, GetInitHandler)
 		public.GET(`/timeline`, GetTimelineHandler)
-
+		public.GET(`/timeline/geojson`, GetTimelineGeoJSONHandler)
+		public.GET(`/timeline/stream`, GetTimelineStreamHandler)
+		public.GET(`/feed.rss`, GetRSSFeedHandler)
+		public.GET(`/feed.atom`, GetAtomFeedHandler)
+		public.GET(`/feed.json`, GetJSONFeedHandler)
+		public.GET(`/media/:id`, GetMediaHandler)
+
+		// resolve the ticker by its slug, which is one of its domains
+		public.GET(`/tickers/:slug/init`, GetInitHandler)
+		public.GET(`/tickers/:slug/timeline`, GetTimelineHandler)
+		public.GET(`/tickers/:slug/timeline/geojson`, GetTimelineGeoJSONHandler)
+		public.GET(`/tickers/:slug/timeline/stream`, GetTimelineStreamHandler)
+		public.GET(`/tickers/:slug/feed.rss`, GetRSSFeedHandler)
+		public.GET(`/tickers/:slug/feed.atom`, GetAtomFeedHandler)
+		public.GET(`/tickers/:slug/feed.json`, GetJSONFeedHandler)
 	}
 
 	return r
 }
 
 func Logger() gin.HandlerFunc {
-	lvl, _ := logrus.ParseLevel(model.Config.LogLevel)
+	lvl, _ := logrus.ParseLevel(Config.LogLevel)
 	logger := logrus.New()
 	logger.SetLevel(lvl)
 
@@ -0,0 +1,213 @@
+package api_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/hub"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestApprovalWorkflow(t *testing.T) {
+	r := setup()
+
+	fb := &fakeBridge{}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	ticker := model.Ticker{
+		ID:              1,
+		Active:          true,
+		Domain:          "demoticker.org",
+		RequireApproval: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+	user.Tickers = []int{1}
+	storage.DB.Save(&user)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"draft"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.True(t, message.Draft)
+			assert.Equal(t, user.ID, message.Author)
+		})
+
+	assert.Equal(t, 0, len(fb.updated))
+	assert.Empty(t, timeline(t, r))
+
+	r.POST("/v1/admin/tickers/1/messages/1/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1003,"message":"messages can not be reviewed by their author"}}`, r.Body.String())
+		})
+
+	r.POST("/v1/admin/tickers/1/messages/1/reject").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"reason":"typo"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.True(t, message.Draft)
+			assert.True(t, message.Rejected)
+			assert.Equal(t, "typo", message.RejectionReason)
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"fixed draft"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.True(t, message.Draft)
+			assert.False(t, message.Rejected)
+		})
+
+	events := hub.Timeline.Subscribe(1)
+	defer events.Close()
+
+	r.POST("/v1/admin/tickers/1/messages/1/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.False(t, message.Draft)
+			assert.Equal(t, 1, message.Reviewer)
+		})
+
+	// the timeline only learns about the published message, not about the replaced draft
+	select {
+	case event := <-events.Events():
+		assert.Equal(t, hub.MessageCreated, event.Type)
+	default:
+		t.Error("no timeline event")
+	}
+	assert.Empty(t, events.Events())
+
+	assert.Equal(t, []string{"fixed draft"}, fb.updated)
+	assert.Equal(t, []string{"fixed draft"}, timeline(t, r))
+
+	r.POST("/v1/admin/tickers/1/messages/2/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"message is not a draft"}}`, r.Body.String())
+		})
+}
+
+func TestApprovalOfEdits(t *testing.T) {
+	r := setup()
+
+	fb := &fakeBridge{}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	ticker := model.Ticker{
+		ID:              1,
+		Active:          true,
+		Domain:          "demoticker.org",
+		RequireApproval: true,
+	}
+	storage.DB.Save(&ticker)
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+	user.Tickers = []int{1}
+	storage.DB.Save(&user)
+
+	reviewer, _ := model.NewUser("reviewer@systemli.org", "password")
+	reviewer.Tickers = []int{1}
+	storage.DB.Save(reviewer)
+	reviewerToken := token("reviewer@systemli.org", "password")
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"draft"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	// reviewers can't approve their own rewrite of a draft
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"rewritten"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages/1/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	var published model.MessageResponse
+	r.POST("/v1/admin/tickers/1/messages/1/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + reviewerToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			published = messageResponse(t, r)
+		})
+
+	assert.Equal(t, []string{"rewritten"}, timeline(t, r))
+
+	// changes of published messages are withdrawn until they are approved
+	path := "/v1/admin/tickers/1/messages/" + strconv.Itoa(published.ID)
+	r.PUT(path).
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"changed"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.True(t, message.Draft)
+			assert.True(t, message.Revised)
+		})
+
+	assert.Empty(t, timeline(t, r))
+
+	r.POST(path+"/approve").
+		SetHeader(map[string]string{"Authorization": "Bearer " + reviewerToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.Equal(t, published.ID, message.ID)
+			assert.False(t, message.Draft)
+			assert.False(t, message.Revised)
+		})
+
+	assert.Equal(t, []string{"changed"}, timeline(t, r))
+	assert.Equal(t, []string{"rewritten"}, fb.updated)
+}
+
+func messageResponse(t *testing.T, r gofight.HTTPResponse) model.MessageResponse {
+	var jres struct {
+		Data map[string]model.MessageResponse `json:"data"`
+	}
+
+	err := json.Unmarshal(r.Body.Bytes(), &jres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return jres.Data["message"]
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/appleboy/gin-jwt"
@@ -11,7 +14,13 @@ import (
 	. "github.com/systemli/ticker/internal/storage"
 )
 
-const UserKey = "user"
+//Keys of the values set by the middlewares in the gin context.
+const (
+	UserKey     = "user"
+	TickerKey   = "ticker"
+	MessageKey  = "message"
+	APITokenKey = "api_token"
+)
 
 //
 func AuthMiddleware() *jwt.GinJWTMiddleware {
@@ -28,6 +37,31 @@ func AuthMiddleware() *jwt.GinJWTMiddleware {
 	}
 }
 
+//TokenMiddleware accepts API tokens as Bearer token and passes all other requests to the JWT middleware.
+func TokenMiddleware(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	jwtMiddleware := mw.MiddlewareFunc()
+
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !IsAPIToken(token) {
+			jwtMiddleware(c)
+			return
+		}
+
+		apiToken, err := FindAPIToken(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, NewJSONErrorResponse(ErrorCodeCredentials, ErrorInvalidAPIToken))
+			return
+		}
+
+		_ = TouchAPIToken(apiToken)
+
+		// same as the identity of the jwt claims, the UserMiddleware loads the user
+		c.Set("userID", float64(apiToken.User))
+		c.Set(APITokenKey, *apiToken)
+	}
+}
+
 //
 func UserMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -48,11 +82,101 @@ func UserMiddleware() gin.HandlerFunc {
 	}
 }
 
+//TwoFactorMiddleware aborts the request when two-factor authentication is enforced and the user hasn't enabled it.
+func TwoFactorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		me, err := Me(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+			return
+		}
+
+		// without a configured secret users couldn't enable it
+		if !me.TOTPEnabled && GetEnforceTwoFactorValue() && Config.TwoFactorAvailable() {
+			c.AbortWithStatusJSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeTwoFactor, ErrorTwoFactorRequired))
+			return
+		}
+	}
+}
+
+//TickerMiddleware loads the ticker of the route when the user has access to it.
+func TickerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := Me(c); err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+			return
+		}
+
+		tickerID, err := strconv.Atoi(c.Param("tickerID"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+
+		if !Allowed(c, tickerID, PermissionReadTicker) {
+			c.AbortWithStatusJSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+			return
+		}
+
+		var ticker Ticker
+		err = DB.One("ID", tickerID, &ticker)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+			return
+		}
+
+		c.Set(TickerKey, ticker)
+	}
+}
+
+//MessageMiddleware loads the message of the route when it belongs to the ticker loaded by the TickerMiddleware.
+func MessageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticker, err := CurrentTicker(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+			return
+		}
+
+		messageID, err := strconv.Atoi(c.Param("messageID"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+
+		var message Message
+		err = DB.One("ID", messageID, &message)
+		if err != nil || message.Ticker != ticker.ID {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+			return
+		}
+
+		c.Set(MessageKey, message)
+	}
+}
+
+//PermissionMiddleware aborts the request when the user lacks the permission on the ticker loaded by the TickerMiddleware.
+func PermissionMiddleware(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticker, err := CurrentTicker(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+			return
+		}
+
+		if !Allowed(c, ticker.ID, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+			return
+		}
+	}
+}
+
 //Authenticator returns the user and the possible authentication error.
 func Authenticator(c *gin.Context) (interface{}, error) {
 	type login struct {
 		Username string `form:"username" json:"username" binding:"required"`
 		Password string `form:"password" json:"password" binding:"required"`
+		OTP      string `form:"otp" json:"otp"`
 	}
 
 	var form login
@@ -60,7 +184,26 @@ func Authenticator(c *gin.Context) (interface{}, error) {
 		return "", jwt.ErrMissingLoginValues
 	}
 
-	return UserAuthenticate(form.Username, form.Password)
+	user, err := UserAuthenticate(form.Username, form.Password)
+	if err != nil || !user.TOTPEnabled {
+		return user, err
+	}
+
+	// the second factor is only asked for after the password is verified
+	if form.OTP == "" {
+		return nil, errors.New(ErrorOTPRequired)
+	}
+	if !user.VerifySecondFactor(form.OTP) {
+		return nil, errors.New(ErrorInvalidOTP)
+	}
+
+	// used one-time passwords and recovery codes are invalidated
+	err = DB.Save(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 //Authorizator returns true when the user is authorized.
@@ -77,7 +220,12 @@ func Authorizator(data interface{}, c *gin.Context) bool {
 
 //
 func Unauthorized(c *gin.Context, code int, message string) {
-	c.JSON(code, NewJSONErrorResponse(ErrorCodeCredentials, message))
+	errorCode := ErrorCodeCredentials
+	if message == ErrorOTPRequired || message == ErrorInvalidOTP {
+		errorCode = ErrorCodeTwoFactor
+	}
+
+	c.JSON(code, NewJSONErrorResponse(errorCode, message))
 }
 
 //
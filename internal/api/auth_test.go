@@ -1,11 +1,15 @@
 package api_test
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/appleboy/gofight"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -45,3 +49,97 @@ func TestUserMiddleware(t *testing.T) {
 	assert.Equal(t, 400, w.Code)
 	assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"user not found"}}`, w.Body.String())
 }
+
+func TestPermissionMiddleware(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{ID: 1, Active: true, Domain: "demoticker.org"}
+	storage.DB.Save(&ticker)
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+	user.Tickers = []int{1}
+	user.SetTickerRole(ticker, model.RoleViewer)
+	storage.DB.Save(&user)
+
+	r.GET("/v1/admin/tickers/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.TickerResponse `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, model.RoleViewer, jres.Data["ticker"].Role)
+			assert.Equal(t, model.RolePermissions[model.RoleViewer], jres.Data["ticker"].Permissions)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers/abc").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers/2").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	// contributors can only submit drafts
+	r.PUT("/v1/admin/tickers/1/users").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"users":[`+strconv.Itoa(user.ID)+`],"role":"contributor"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.True(t, messageResponse(t, r).Draft)
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"edited"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	// only owners can change the ticker settings
+	r.PUT("/v1/admin/tickers/1/telegram").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"active":false}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.PUT("/v1/admin/tickers/1/users").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"users":[`+strconv.Itoa(user.ID)+`],"role":"owner"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.PUT("/v1/admin/tickers/1/telegram").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"active":false}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+}
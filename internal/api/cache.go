@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+var (
+	//CacheTTL limits the age of cached responses. Changes outside of the admin API, like the delivery
+	//to bridges, don't invalidate the cache.
+	CacheTTL = time.Minute
+	//CacheMaxEntries is the maximum number of cached responses per domain.
+	CacheMaxEntries = 100
+)
+
+//responseCache holds the rendered public responses per domain. The version is increased with every
+//invalidation, so responses which were rendered during a change are not stored.
+var responseCache = struct {
+	sync.Mutex
+	version int
+	domains map[string]map[string]cacheEntry
+}{
+	domains: make(map[string]map[string]cacheEntry),
+}
+
+type cacheEntry struct {
+	ticker   int
+	modified time.Time
+	created  time.Time
+	data     []byte
+}
+
+//serveCached responds with the cached response for the domain and returns true on a hit.
+func serveCached(c *gin.Context, domain, key string) bool {
+	responseCache.Lock()
+	entry, ok := responseCache.domains[domain][key]
+	responseCache.Unlock()
+
+	handler := prepareHandler(c.HandlerName())
+	if !ok || time.Since(entry.created) >= CacheTTL {
+		cacheCnt.WithLabelValues(handler, "miss").Inc()
+		return false
+	}
+
+	cacheCnt.WithLabelValues(handler, "hit").Inc()
+	conditionalData(c, entry.modified, "application/json; charset=utf-8", entry.data)
+
+	return true
+}
+
+//cacheVersion returns the current version of the cache. It has to be read before loading the data.
+func cacheVersion() int {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	return responseCache.version
+}
+
+//cachedJSON stores the response for the domain, unless the cache was invalidated since the given version,
+//and writes it. Responses without a ticker are not stored, so unknown domains can't fill the cache.
+func cachedJSON(c *gin.Context, domain, key string, version, tickerID int, modified time.Time, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	responseCache.Lock()
+	if tickerID != 0 && responseCache.version == version {
+		entries, ok := responseCache.domains[domain]
+		if !ok {
+			entries = make(map[string]cacheEntry)
+			responseCache.domains[domain] = entries
+		}
+		if len(entries) >= CacheMaxEntries {
+			// evict any entry, the cache is meant for the few common requests
+			for k := range entries {
+				delete(entries, k)
+				break
+			}
+		}
+		entries[key] = cacheEntry{ticker: tickerID, modified: modified, created: time.Now(), data: data}
+	}
+	responseCache.Unlock()
+
+	conditionalData(c, modified, "application/json; charset=utf-8", data)
+}
+
+//invalidateCache removes the cached responses of the ticker, or all responses for tickerID 0.
+func invalidateCache(tickerID int) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	responseCache.version++
+
+	for domain, entries := range responseCache.domains {
+		for key, entry := range entries {
+			if tickerID == 0 || entry.ticker == tickerID {
+				delete(entries, key)
+			}
+		}
+		if len(entries) == 0 {
+			delete(responseCache.domains, domain)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+//modifications tracks the last change of the public data for every ticker. Changes before the start
+//of the process are unknown, so the start time is the earliest modification.
+var modifications = struct {
+	sync.Mutex
+	start    time.Time
+	settings time.Time
+	tickers  map[int]time.Time
+}{
+	start:   time.Now(),
+	tickers: make(map[int]time.Time),
+}
+
+//touchTicker records a change of the public data of the ticker.
+func touchTicker(tickerID int) {
+	modifications.Lock()
+	modifications.tickers[tickerID] = time.Now()
+	modifications.Unlock()
+
+	invalidateCache(tickerID)
+	resetOrigins()
+}
+
+//touchSettings records a change of the settings, which are part of the public data of all tickers.
+func touchSettings() {
+	modifications.Lock()
+	modifications.settings = time.Now()
+	modifications.Unlock()
+
+	invalidateCache(0)
+}
+
+//lastModified returns the time of the last change of the public data for the ticker.
+func lastModified(tickerID int) time.Time {
+	modifications.Lock()
+	defer modifications.Unlock()
+
+	modified := modifications.start
+	if modifications.settings.After(modified) {
+		modified = modifications.settings
+	}
+	if t, ok := modifications.tickers[tickerID]; ok && t.After(modified) {
+		modified = t
+	}
+
+	return modified
+}
+
+//conditionalData writes the response with ETag and Last-Modified headers. It responds with 304
+//when the client already has the current version.
+func conditionalData(c *gin.Context, modified time.Time, contentType string, data []byte) {
+	sum := sha1.Sum(data)
+	etag := `W/"` + hex.EncodeToString(sum[:]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+
+	if notModified(c.Request, etag, modified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+//notModified evaluates the conditional headers as defined in RFC 7232. If-Modified-Since is ignored
+//when If-None-Match is present.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(ims)
+}
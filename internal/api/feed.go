@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/feeds"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+	. "github.com/systemli/ticker/internal/util"
+)
+
+//FeedTitleLength is the maximum length for the titles of feed items.
+const FeedTitleLength = 80
+
+//JSONFeedVersion is the URL of the implemented JSON Feed specification.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version     string            `json:"version"`
+	Title       string            `json:"title"`
+	HomePageURL string            `json:"home_page_url,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Authors     []*jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []*jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	ContentText   string     `json:"content_text"`
+	DatePublished time.Time  `json:"date_published"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+}
+
+//GetRSSFeedHandler returns the latest messages of a ticker as RSS 2.0 feed.
+func GetRSSFeedHandler(c *gin.Context) {
+	ticker, feed, ok := tickerFeed(c)
+	if !ok {
+		return
+	}
+
+	rss, err := feed.ToRss()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	conditionalData(c, lastModified(ticker.ID), "application/rss+xml; charset=utf-8", []byte(rss))
+}
+
+//GetAtomFeedHandler returns the latest messages of a ticker as Atom feed.
+func GetAtomFeedHandler(c *gin.Context) {
+	ticker, feed, ok := tickerFeed(c)
+	if !ok {
+		return
+	}
+
+	atom, err := feed.ToAtom()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	conditionalData(c, lastModified(ticker.ID), "application/atom+xml; charset=utf-8", []byte(atom))
+}
+
+//GetJSONFeedHandler returns the latest messages of a ticker as JSON Feed 1.1.
+func GetJSONFeedHandler(c *gin.Context) {
+	ticker, feed, ok := tickerFeed(c)
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(newJSONFeed(feed))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	conditionalData(c, lastModified(ticker.ID), "application/feed+json; charset=utf-8", data)
+}
+
+func tickerFeed(c *gin.Context) (*Ticker, *feeds.Feed, bool) {
+	domain, err := GetDomain(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return nil, nil, false
+	}
+
+	// inactive tickers don't reveal their information
+	ticker, err := FindTicker(domain)
+	if err != nil || !ticker.Active {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return nil, nil, false
+	}
+
+	messages, err := FindByTicker(ticker, NewPagination(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return nil, nil, false
+	}
+
+	return ticker, newFeed(ticker, messages), true
+}
+
+//newFeed builds the feed for the ticker with the given messages, newest first.
+func newFeed(ticker *Ticker, messages []Message) *feeds.Feed {
+	link := ticker.Information.URL
+	if link == "" {
+		link = "https://" + ticker.Domain
+	}
+
+	feed := &feeds.Feed{
+		Id:          link,
+		Title:       ticker.Title,
+		Description: ticker.Description,
+		Link:        &feeds.Link{Href: link},
+		Created:     ticker.CreationDate,
+		Updated:     ticker.CreationDate,
+	}
+
+	if ticker.Information.Author != "" || ticker.Information.Email != "" {
+		feed.Author = &feeds.Author{Name: ticker.Information.Author, Email: ticker.Information.Email}
+	}
+
+	for _, message := range messages {
+		item := &feeds.Item{
+			Id:          messageGUID(ticker, message),
+			Title:       feedTitle(message.Text),
+			Link:        &feeds.Link{Href: link},
+			Description: message.Text,
+			Created:     message.CreationDate,
+			Updated:     message.CreationDate,
+		}
+		if message.EditedAt != nil {
+			item.Updated = *message.EditedAt
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+
+		feed.Add(item)
+	}
+
+	return feed
+}
+
+//newJSONFeed converts the feed to JSON Feed 1.1.
+func newJSONFeed(feed *feeds.Feed) *jsonFeed {
+	jf := &jsonFeed{
+		Version:     JSONFeedVersion,
+		Title:       feed.Title,
+		HomePageURL: feed.Link.Href,
+		Description: feed.Description,
+		Items:       []*jsonFeedItem{},
+	}
+
+	if feed.Author != nil {
+		jf.Authors = append(jf.Authors, &jsonFeedAuthor{Name: feed.Author.Name})
+	}
+
+	for _, item := range feed.Items {
+		ji := &jsonFeedItem{
+			ID:            item.Id,
+			URL:           item.Link.Href,
+			Title:         item.Title,
+			ContentText:   item.Description,
+			DatePublished: item.Created,
+		}
+		if !item.Updated.Equal(item.Created) {
+			updated := item.Updated
+			ji.DateModified = &updated
+		}
+
+		jf.Items = append(jf.Items, ji)
+	}
+
+	return jf
+}
+
+//messageGUID returns a tag URI (RFC 4151) which identifies the message independent of the ticker's url.
+func messageGUID(ticker *Ticker, message Message) string {
+	return fmt.Sprintf("tag:%s,%s:message-%d", ticker.Domain, message.CreationDate.Format("2006-01-02"), message.ID)
+}
+
+//feedTitle shortens the text to the first line with at most FeedTitleLength characters.
+func feedTitle(text string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if utf8.RuneCountInString(title) <= FeedTitleLength {
+		return title
+	}
+
+	runes := []rune(title)[:FeedTitleLength-1]
+	if i := strings.LastIndex(string(runes), " "); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+
+	return string(runes) + "…"
+}
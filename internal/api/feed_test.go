@@ -0,0 +1,146 @@
+package api_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestGetFeedHandlers(t *testing.T) {
+	r := setup()
+
+	r.GET("/v1/feed.rss?origin=demoticker.org").
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+		})
+
+	ticker := model.Ticker{
+		ID:          1,
+		Active:      true,
+		Domain:      "demoticker.org",
+		Title:       "Demoticker",
+		Description: "Description",
+		Information: model.Information{Author: "Systemli"},
+	}
+
+	storage.DB.Save(&ticker)
+
+	for _, text := range []string{"first message", "second message\nwith more details"} {
+		r.POST("/v1/admin/tickers/1/messages").
+			SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+			SetBody(`{"text":"`+strings.Replace(text, "\n", `\n`, -1)+`"}`).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 200, r.Code)
+			})
+	}
+
+	r.GET("/v1/feed.rss").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Equal(t, "application/rss+xml; charset=utf-8", r.HeaderMap.Get("Content-Type"))
+
+			var rss struct {
+				Channel struct {
+					Title          string `xml:"title"`
+					Description    string `xml:"description"`
+					ManagingEditor string `xml:"managingEditor"`
+					Items          []struct {
+						Title       string `xml:"title"`
+						Description string `xml:"description"`
+						GUID        string `xml:"guid"`
+						PubDate     string `xml:"pubDate"`
+					} `xml:"item"`
+				} `xml:"channel"`
+			}
+
+			err := xml.Unmarshal(r.Body.Bytes(), &rss)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "Demoticker", rss.Channel.Title)
+			assert.Equal(t, "Description", rss.Channel.Description)
+			assert.Contains(t, rss.Channel.ManagingEditor, "Systemli")
+			assert.Equal(t, 2, len(rss.Channel.Items))
+			assert.Equal(t, "second message", rss.Channel.Items[0].Title)
+			assert.Equal(t, "second message\nwith more details", rss.Channel.Items[0].Description)
+			assert.Regexp(t, `^tag:demoticker.org,\d{4}-\d{2}-\d{2}:message-2$`, rss.Channel.Items[0].GUID)
+			assert.NotEmpty(t, rss.Channel.Items[0].PubDate)
+		})
+
+	r.GET("/v1/feed.atom").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Equal(t, "application/atom+xml; charset=utf-8", r.HeaderMap.Get("Content-Type"))
+
+			var atom struct {
+				Title   string `xml:"title"`
+				Author  string `xml:"author>name"`
+				Entries []struct {
+					ID    string `xml:"id"`
+					Title string `xml:"title"`
+				} `xml:"entry"`
+			}
+
+			err := xml.Unmarshal(r.Body.Bytes(), &atom)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "Demoticker", atom.Title)
+			assert.Equal(t, "Systemli", atom.Author)
+			assert.Equal(t, 2, len(atom.Entries))
+			assert.Equal(t, "first message", atom.Entries[1].Title)
+		})
+
+	r.GET("/v1/feed.json?origin=demoticker.org").
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Equal(t, "application/feed+json; charset=utf-8", r.HeaderMap.Get("Content-Type"))
+
+			var feed struct {
+				Version string `json:"version"`
+				Title   string `json:"title"`
+				Authors []struct {
+					Name string `json:"name"`
+				} `json:"authors"`
+				Items []struct {
+					ID          string `json:"id"`
+					ContentText string `json:"content_text"`
+				} `json:"items"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &feed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+			assert.Equal(t, "Demoticker", feed.Title)
+			assert.Equal(t, "Systemli", feed.Authors[0].Name)
+			assert.Equal(t, 2, len(feed.Items))
+			assert.Equal(t, "first message", feed.Items[1].ContentText)
+		})
+
+	ticker.Active = false
+	storage.DB.Save(&ticker)
+
+	for _, path := range []string{"/v1/feed.rss", "/v1/feed.atom", "/v1/feed.json"} {
+		r.GET(path).
+			SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 404, r.Code)
+				assert.NotContains(t, r.Body.String(), "Demoticker")
+			})
+	}
+}
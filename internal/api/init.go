@@ -11,6 +11,10 @@ import (
 //GetInitHandler returns the basic settings for the ticker.
 func GetInitHandler(c *gin.Context) {
 	domain, err := GetDomain(c)
+	if serveCached(c, domain, "init") {
+		return
+	}
+	version := cacheVersion()
 
 	type settings struct {
 		RefreshInterval  int         `json:"refresh_interval,omitempty"`
@@ -25,7 +29,7 @@ func GetInitHandler(c *gin.Context) {
 	if err != nil || !ticker.Active {
 		s.InactiveSettings = GetInactiveSettings().Value
 
-		c.JSON(http.StatusOK, JSONResponse{
+		cachedJSON(c, domain, "init", version, ticker.ID, lastModified(ticker.ID), JSONResponse{
 			Data:   map[string]interface{}{"ticker": nil, "settings": s},
 			Status: ResponseSuccess,
 			Error:  nil,
@@ -33,11 +37,15 @@ func GetInitHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, JSONResponse{
-		//TODO: Build NewTickerPublicResponse to hide unnecessary information
-		Data:   map[string]interface{}{"ticker": NewTickerResponse(ticker), "settings": s},
+	pinned, err := FindPinnedByTicker(ticker)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	cachedJSON(c, domain, "init", version, ticker.ID, lastModified(ticker.ID), JSONResponse{
+		Data:   map[string]interface{}{"ticker": NewPublicTickerResponse(ticker), "settings": s, "pinned": NewPublicMessagesResponse(pinned)},
 		Status: ResponseSuccess,
 		Error:  nil,
 	})
-	return
 }
@@ -71,9 +71,10 @@ func TestGetInitHandler(t *testing.T) {
 
 			assert.Nil(t, data.Error)
 			assert.Equal(t, model.ResponseSuccess, data.Status)
-			assert.Equal(t, 2, len(data.Data))
+			assert.Equal(t, 3, len(data.Data))
 
 			ticker := data.Data["ticker"]
 			assert.NotNil(t, ticker)
+			assert.Nil(t, data.Data["pinned"])
 		})
 }
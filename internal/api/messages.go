@@ -3,41 +3,23 @@ package api
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/asdine/storm"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/hub"
 	. "github.com/systemli/ticker/internal/model"
 	. "github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/webhook"
 )
 
 //GetMessagesHandler returns all Messages with paging
 func GetMessagesHandler(c *gin.Context) {
-	me, err := Me(c)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
-		return
-	}
-
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
-		return
-	}
-
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
-	}
-
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	ticker, err := CurrentTicker(c)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
@@ -45,7 +27,7 @@ func GetMessagesHandler(c *gin.Context) {
 
 	var messages []Message
 	//TODO: Pagination
-	err = DB.Find("Ticker", tickerID, &messages, storm.Reverse())
+	err = DB.Find("Ticker", ticker.ID, &messages, storm.Reverse())
 	if err != nil {
 		if err.Error() == "not found" {
 			c.JSON(http.StatusOK, NewJSONSuccessResponse("messages", []string{}))
@@ -56,52 +38,236 @@ func GetMessagesHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("messages", NewMessagesResponse(messages)))
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("messages", messagesResponseWithDeliveries(messages)))
 }
 
 //GetMessageHandler returns a Message for the given id
 func GetMessageHandler(c *gin.Context) {
+	message, err := CurrentMessage(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
+}
+
+//PostMessageHandler creates and returns a new Message
+func PostMessageHandler(c *gin.Context) {
+	var body struct {
+		Text        string     `json:"text" binding:"required"`
+		Geometry    *Geometry  `json:"geometry"`
+		Attachments []int      `json:"attachments"`
+		PublishAt   *time.Time `json:"publish_at"`
+		Severity    string     `json:"severity"`
+		Pinned      bool       `json:"pinned"`
+	}
+	err := c.Bind(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	if body.Severity != "" {
+		err = ValidateSeverity(body.Severity)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
+	if body.Geometry != nil {
+		err = body.Geometry.Validate()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
 	me, err := Me(c)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	if len(body.Attachments) > MaxAttachments {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, fmt.Sprintf("Attachments: at most %d attachments are allowed", MaxAttachments)))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+	message := NewMessage()
+	message.Text = body.Text
+	message.Geometry = body.Geometry
+	message.Author = me.ID
+	message.Draft = !Allowed(c, ticker.ID, PermissionPublishMessages)
+	message.Pinned = body.Pinned
+	if body.Severity != "" {
+		message.Severity = body.Severity
+	}
+
+	for _, uploadID := range body.Attachments {
+		var upload Upload
+		err = DB.One("ID", uploadID, &upload)
+		if err != nil || upload.Ticker != ticker.ID {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, fmt.Sprintf("Attachments: upload %d not found", uploadID)))
 			return
 		}
+
+		message.Attachments = append(message.Attachments, upload.Attachment())
 	}
 
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	if body.PublishAt != nil {
+		message.PublishAt = *body.PublishAt
+	}
+
+	err = SubmitMessage(ticker, message)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{*message})[0]))
+}
+
+//SubmitMessage stores the Message as draft when the ticker requires approval or the Message is
+//already marked as draft. Otherwise it is scheduled or published immediately.
+func SubmitMessage(ticker Ticker, message *Message) error {
+	if ticker.RequireApproval || message.Draft {
+		message.Ticker = ticker.ID
+		message.Draft = true
+
+		err := DB.Save(message)
+		if err != nil {
+			return err
+		}
+
+		messageChanged(hub.MessageCreated, *message)
+
+		return nil
+	}
+
+	if message.PublishAt.After(time.Now()) {
+		return ScheduleMessage(ticker, message)
+	}
+
+	return PublishMessage(ticker, message)
+}
+
+//PublishMessage appends the ticker hashtags, stores the Message and sends it to all enabled bridges.
+//Failed bridge deliveries are kept in the outbox and retried later.
+func PublishMessage(ticker Ticker, message *Message) error {
+	message.Ticker = ticker.ID
+	appendHashtags(ticker, message)
+
+	err := DB.Save(message)
+	if err != nil {
+		return err
+	}
+
+	distributeMessage(ticker, message)
+
+	return nil
+}
+
+//ScheduleMessage stores the Message without publishing it. It is published by the scheduler when due.
+func ScheduleMessage(ticker Ticker, message *Message) error {
+	message.Ticker = ticker.ID
+	message.Scheduled = true
+
+	err := DB.Save(message)
+	if err != nil {
+		return err
+	}
+
+	messageChanged(hub.MessageCreated, *message)
+
+	return nil
+}
+
+func appendHashtags(ticker Ticker, message *Message) {
+	if len(ticker.Hashtags) > 0 {
+		message.Text = fmt.Sprintf(`%s %s`, message.Text, strings.Join(ticker.Hashtags, " "))
+	}
+}
+
+//isPublic returns true if the Message is shown in the public timeline.
+func isPublic(message Message) bool {
+	return !message.Draft && !message.Scheduled
+}
+
+//distributeMessage sends a stored Message to the bridges and webhooks. The message is published already,
+//so failures of the bridges are only logged.
+func distributeMessage(ticker Ticker, message *Message) {
+	err := bridge.Deliver(ticker, message)
+	if err != nil {
+		log.WithError(err).WithField("message", message.ID).Error("could not save references of message")
+	}
+
+	webhook.Dispatch(EventMessageCreated, ticker.ID, NewMessageResponse(*message))
+	messageChanged(hub.MessageCreated, *message)
+}
+
+//DeleteTickerHandler deletes a existing Ticker
+func DeleteMessageHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	var message Message
-	messageID, err := strconv.Atoi(c.Param("messageID"))
-	err = DB.One("ID", messageID, &message)
+	message, err := CurrentMessage(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", NewMessageResponse(message)))
+	// fails when the message is gone, e.g. because a scheduled message was published under a new ID
+	err = DB.DeleteStruct(&message)
+	if err == storm.ErrNotFound {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	for name, err := range bridge.Delete(ticker, message) {
+		log.WithError(err).WithField("bridge", name).Error("could not delete message")
+	}
+
+	err = DeleteDeliveries("Message", message.ID)
+	if err != nil {
+		log.WithError(err).WithField("message", message.ID).Error("could not delete deliveries")
+	}
+
+	err = DeleteRevisions("Message", message.ID)
+	if err != nil {
+		log.WithError(err).WithField("message", message.ID).Error("could not delete revisions")
+	}
+
+	webhook.Dispatch(EventMessageDeleted, ticker.ID, NewMessageResponse(message))
+	messageChanged(hub.MessageDeleted, message)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   nil,
+		"status": ResponseSuccess,
+		"error":  nil,
+	})
 }
 
-//PostMessageHandler creates and returns a new Message
-func PostMessageHandler(c *gin.Context) {
+//PutMessageHandler changes the text of a Message and keeps the previous version as revision
+func PutMessageHandler(c *gin.Context) {
 	var body struct {
-		Text string `json:"text" binding:"required"`
+		Text     string  `json:"text" binding:"required"`
+		Severity *string `json:"severity"`
+		Pinned   *bool   `json:"pinned"`
 	}
 	err := c.Bind(&body)
 	if err != nil {
@@ -109,116 +275,290 @@ func PostMessageHandler(c *gin.Context) {
 		return
 	}
 
+	if body.Severity != nil {
+		err = ValidateSeverity(*body.Severity)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
 	me, err := Me(c)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
+	message, err := CurrentMessage(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
 	}
 
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+	textChanged := message.Text != body.Text
+	if !textChanged && body.Severity == nil && body.Pinned == nil {
+		c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
 		return
 	}
 
-	message := NewMessage()
-	message.Text = body.Text
-	message.Ticker = tickerID
+	var revision *MessageRevision
+	if textChanged {
+		revision = NewMessageRevision(message)
 
-	if len(ticker.Hashtags) > 0 {
-		message.Text = fmt.Sprintf(`%s %s`, message.Text, strings.Join(ticker.Hashtags, " "))
+		now := time.Now()
+		message.Text = body.Text
+		message.EditedAt = &now
+		message.EditedBy = me.ID
+		// an edited draft has to be reviewed again
+		message.Rejected = false
 	}
 
-	if ticker.Twitter.Active {
-		tweet, err := bridge.Twitter.Update(ticker, *message)
-		if err == nil {
-			message.Tweet = Tweet{ID: tweet.IDStr, UserName: tweet.User.ScreenName}
-		} else {
-			log.Error(err)
-		}
+	// in tickers with approval the changed text of a message has to be reviewed before it's published
+	withdrawn := textChanged && ticker.RequireApproval && isPublic(message)
+	if textChanged && ticker.RequireApproval && !message.Draft {
+		message.Revised = withdrawn
+		message.Draft = true
+		message.Scheduled = false
 	}
 
-	err = DB.Save(message)
+	if body.Severity != nil {
+		message.Severity = *body.Severity
+	}
+	if body.Pinned != nil {
+		message.Pinned = *body.Pinned
+	}
+
+	// a scheduled message may have been published under a new ID in the meantime
+	err = UpdateMessage(&message, revision)
+	if err == storm.ErrNotFound {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", NewMessageResponse(*message)))
+	if textChanged && !message.Draft {
+		for name, err := range bridge.Edit(ticker, message) {
+			log.WithError(err).WithField("bridge", name).Error("could not edit message")
+		}
+	}
+
+	messageChanged(hub.MessageUpdated, message)
+	if withdrawn {
+		timelineChanged(hub.MessageDeleted, message)
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
 }
 
-//DeleteTickerHandler deletes a existing Ticker
-func DeleteMessageHandler(c *gin.Context) {
-	me, err := Me(c)
+//GetMessageRevisionsHandler returns the previous versions of a Message
+func GetMessageRevisionsHandler(c *gin.Context) {
+	message, err := CurrentMessage(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	revisions, err := FindRevisionsByMessage(message.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("revisions", NewMessageRevisionsResponse(revisions)))
+}
+
+//ApproveMessageHandler publishes a draft Message. Drafts can't be approved by their author.
+func ApproveMessageHandler(c *gin.Context) {
+	me, ticker, message, ok := findDraft(c)
+	if !ok {
+		return
+	}
+
+	text := message.Text
+	approve := func(m *Message) {
+		m.Draft = false
+		m.Rejected = false
+		m.RejectionReason = ""
+		m.Reviewer = me.ID
+	}
+	approve(&message)
+
+	var err error
+	if message.Revised {
+		err = republishMessage(ticker, &message)
+	} else if message.PublishAt.After(time.Now()) {
+		message.Scheduled = true
+		err = UpdateMessage(&message, nil)
+		if err == nil {
+			messageChanged(hub.MessageUpdated, message)
 		}
+	} else {
+		// only the reviewed version of the draft is published
+		err = publishStoredMessage(ticker, &message, func(stored *Message) bool {
+			if !stored.Draft || stored.Text != text {
+				return false
+			}
+			approve(stored)
+
+			return true
+		})
+	}
+	if err == storm.ErrNotFound {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
+	}
+	if err == errMessageChanged {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, ErrorMessageNotDraft))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
 	}
 
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
+}
+
+//republishMessage publishes the approved changes of a revised Message, which was published before.
+func republishMessage(ticker Ticker, message *Message) error {
+	message.Revised = false
+
+	err := UpdateMessage(message, nil)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+		return err
+	}
+
+	for name, err := range bridge.Edit(ticker, *message) {
+		log.WithError(err).WithField("bridge", name).Error("could not edit message")
+	}
+
+	hub.Admin.Publish(hub.Event{Type: hub.MessageUpdated, Ticker: message.Ticker, Data: NewMessageResponse(*message)})
+	timelineChanged(hub.MessageCreated, *message)
+
+	return nil
+}
+
+//RejectMessageHandler rejects a draft Message. Drafts can't be rejected by their author.
+func RejectMessageHandler(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	// the reason is optional
+	_ = c.ShouldBindJSON(&body)
+
+	me, _, message, ok := findDraft(c)
+	if !ok {
 		return
 	}
 
-	messageID, err := strconv.Atoi(c.Param("messageID"))
+	message.Rejected = true
+	message.RejectionReason = body.Reason
+	message.Reviewer = me.ID
+
+	err := UpdateMessage(&message, nil)
+	if err == storm.ErrNotFound {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
+	messageChanged(hub.MessageUpdated, message)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
+}
+
+func findDraft(c *gin.Context) (User, Ticker, Message, bool) {
+	var ticker Ticker
 	var message Message
 
-	err = DB.One("ID", messageID, &message)
+	me, err := Me(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		return me, ticker, message, false
+	}
+
+	ticker, err = CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return me, ticker, message, false
+	}
+
+	message, err = CurrentMessage(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
+		return me, ticker, message, false
+	}
+
+	if !message.Draft {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, ErrorMessageNotDraft))
+		return me, ticker, message, false
+	}
+
+	// reviewers can't approve their own changes of the text
+	if message.Author == me.ID || message.EditedBy == me.ID {
+		c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorApprovalByAuthor))
+		return me, ticker, message, false
+	}
+
+	return me, ticker, message, true
+}
+
+//RetryMessageHandler retries all unsent bridge deliveries of a Message immediately
+func RetryMessageHandler(c *gin.Context) {
+	message, err := CurrentMessage(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
 		return
 	}
 
-	if message.Tweet.ID != "" {
-		err = bridge.Twitter.Delete(ticker, message.Tweet.ID)
-		if err != nil {
-			log.Error(err)
-		}
+	err = bridge.Retry(message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
 	}
 
-	err = DB.DeleteStruct(&message)
+	//Reload the message to include the references set by the bridges
+	err = DB.One("ID", message.ID, &message)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorMessageNotFound))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":   nil,
-		"status": ResponseSuccess,
-		"error":  nil,
-	})
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("message", messagesResponseWithDeliveries([]Message{message})[0]))
+}
+
+func messagesResponseWithDeliveries(messages []Message) []*MessageResponse {
+	var ids []int
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	deliveries, err := FindDeliveriesByMessages(ids)
+	if err != nil {
+		log.WithError(err).Error("could not find deliveries")
+	}
+
+	byMessage := make(map[int][]BridgeDelivery)
+	for _, delivery := range deliveries {
+		byMessage[delivery.Message] = append(byMessage[delivery.Message], delivery)
+	}
+
+	mr := NewMessagesResponse(messages)
+	for _, m := range mr {
+		m.Deliveries = NewBridgeDeliveriesResponse(byMessage[m.ID])
+	}
+
+	return mr
 }
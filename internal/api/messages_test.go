@@ -2,17 +2,47 @@ package api_test
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/appleboy/gofight"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/bridge"
 	"github.com/systemli/ticker/internal/model"
 	"github.com/systemli/ticker/internal/storage"
 	"strings"
 )
 
+type fakeBridge struct {
+	err     error
+	updated []string
+	deleted []string
+}
+
+func (fb *fakeBridge) Enabled(ticker model.Ticker) bool {
+	return ticker.Active
+}
+
+func (fb *fakeBridge) Update(ticker model.Ticker, message *model.Message) error {
+	fb.updated = append(fb.updated, message.Text)
+	if fb.err != nil {
+		return fb.err
+	}
+	message.Tweet = model.Tweet{ID: "1", UserName: "fake"}
+	return nil
+}
+
+func (fb *fakeBridge) Delete(ticker model.Ticker, message model.Message) error {
+	fb.deleted = append(fb.deleted, message.Tweet.ID)
+	return nil
+}
+
+func (fb *fakeBridge) Verify(ticker model.Ticker) error {
+	return nil
+}
+
 func TestGetMessagesHandler(t *testing.T) {
 	r := setup()
 
@@ -52,7 +82,7 @@ func TestGetMessageHandler(t *testing.T) {
 		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
 		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
 			assert.Equal(t, 404, r.Code)
-			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"not found"}}`, strings.TrimSpace(r.Body.String()))
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"message not found"}}`, strings.TrimSpace(r.Body.String()))
 		})
 
 	r.GET("/v1/admin/tickers/1/messages/1").
@@ -128,6 +158,30 @@ func TestGetMessageHandler(t *testing.T) {
 			assert.Equal(t, 1, len(response.Data))
 			assert.Equal(t, "text", response.Data["message"].Text)
 		})
+
+	// the message can't be read or deleted through another ticker of the user
+	storage.DB.Save(&model.Ticker{ID: 2, Active: true})
+	user.Tickers = []int{1, 2}
+	storage.DB.Save(&user)
+
+	r.GET("/v1/admin/tickers/2/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"message not found"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.DELETE("/v1/admin/tickers/2/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
 }
 
 func TestPostMessageHandler(t *testing.T) {
@@ -235,3 +289,213 @@ func TestDeleteMessageHandler(t *testing.T) {
 			assert.Nil(t, jres.Error)
 		})
 }
+
+func TestMessageHandlerBridges(t *testing.T) {
+	r := setup()
+
+	fb := &fakeBridge{}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text": "message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data   map[string]model.MessageResponse `json:"data"`
+				Status string                           `json:"status"`
+				Error  interface{}                      `json:"error"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "1", jres.Data["message"].TweetID)
+			assert.Equal(t, "fake", jres.Data["message"].TweetUser)
+		})
+
+	assert.Equal(t, []string{"message"}, fb.updated)
+
+	r.DELETE("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	assert.Equal(t, []string{"1"}, fb.deleted)
+}
+
+func TestRetryMessageHandler(t *testing.T) {
+	r := setup()
+
+	fb := &fakeBridge{err: errors.New("unavailable")}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text": "message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Empty(t, jres.Data["message"].TweetID)
+			assert.Equal(t, 1, len(jres.Data["message"].Deliveries))
+			assert.Equal(t, "fake", jres.Data["message"].Deliveries[0].Bridge)
+			assert.Equal(t, model.DeliveryStatePending, jres.Data["message"].Deliveries[0].State)
+			assert.Equal(t, "unavailable", jres.Data["message"].Deliveries[0].LastError)
+		})
+
+	r.POST("/v1/admin/tickers/2/messages/1/retry").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"ticker not found"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	fb.err = nil
+
+	r.POST("/v1/admin/tickers/1/messages/1/retry").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "1", jres.Data["message"].TweetID)
+			assert.Equal(t, model.DeliveryStateSent, jres.Data["message"].Deliveries[0].State)
+			assert.Empty(t, jres.Data["message"].Deliveries[0].LastError)
+		})
+
+	r.DELETE("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	deliveries, _ := storage.FindDeliveriesByMessages([]int{1})
+	assert.Empty(t, deliveries)
+}
+
+func TestPutMessageHandler(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	message := model.NewMessage()
+	message.Ticker = 1
+	message.Text = "mesage"
+	message.Author = 2
+
+	storage.DB.Save(message)
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/2").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"message not found"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "message", jres.Data["message"].Text)
+			assert.NotNil(t, jres.Data["message"].EditedAt)
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message!"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers/1/messages/1/revisions").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.MessageRevisionResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			revisions := jres.Data["revisions"]
+			assert.Equal(t, 2, len(revisions))
+			assert.Equal(t, "mesage", revisions[0].Text)
+			assert.Equal(t, 2, revisions[0].User)
+			assert.Equal(t, "message", revisions[1].Text)
+			assert.Equal(t, 1, revisions[1].User)
+		})
+
+	r.DELETE("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	revisions, _ := storage.FindRevisionsByMessage(1)
+	assert.Empty(t, revisions)
+}
@@ -3,13 +3,27 @@ package api
 import (
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
 )
 
+//unknownOrigin labels the requests for domains which don't belong to a ticker.
+const unknownOrigin = "unknown"
+
+//origins keeps the domains of the tickers in memory, so the labels of the requests don't hit the database.
+//They are refreshed after changes of a ticker.
+var origins struct {
+	sync.Mutex
+	domains map[string]string
+}
+
 var (
 	reqCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "http_requests_total",
@@ -20,6 +34,11 @@ var (
 		Name: "http_request_duration_seconds",
 		Help: "The HTTP requests latency in seconds",
 	}, []string{"handler", "origin", "code"})
+
+	cacheCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "response_cache_requests_total",
+		Help: "The total number of cache lookups for public responses",
+	}, []string{"handler", "result"})
 )
 
 // NewPrometheus returns the Gin Middleware for collecting basic http metrics.
@@ -32,6 +51,12 @@ func NewPrometheus() gin.HandlerFunc {
 	if err != nil {
 		log.WithError(err).Error(`"reqDur" could not be registered in Prometheus`)
 	}
+	err = prometheus.Register(cacheCnt)
+	if err != nil {
+		log.WithError(err).Error(`"cacheCnt" could not be registered in Prometheus`)
+	}
+
+	resetOrigins()
 
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -54,11 +79,53 @@ func prepareHandler(h string) string {
 	return s[len(s)-1]
 }
 
+//prepareOrigin returns the primary domain of the requested ticker. Arbitrary domains from the request aren't used
+//as labels, so clients can't create unlimited time series.
 func prepareOrigin(c *gin.Context) string {
 	domain, err := GetDomain(c)
 	if err != nil {
 		return ""
 	}
 
-	return domain
+	origins.Lock()
+	defer origins.Unlock()
+
+	if origins.domains == nil {
+		origins.domains, err = loadOrigins()
+		if err != nil {
+			log.WithError(err).Error("could not load the domains of the tickers")
+			return unknownOrigin
+		}
+	}
+
+	if origin, ok := origins.domains[domain]; ok {
+		return origin
+	}
+
+	return unknownOrigin
+}
+
+//resetOrigins drops the known domains, they are loaded again with the next request.
+func resetOrigins() {
+	origins.Lock()
+	origins.domains = nil
+	origins.Unlock()
+}
+
+//loadOrigins maps the domains and aliases of all tickers to their primary domain.
+func loadOrigins() (map[string]string, error) {
+	var tickers []Ticker
+	err := DB.All(&tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make(map[string]string)
+	for _, ticker := range tickers {
+		for _, domain := range ticker.Domains() {
+			domains[domain] = ticker.Domain
+		}
+	}
+
+	return domains, nil
 }
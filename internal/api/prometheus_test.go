@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestPrometheusOrigin(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	engine := api.API()
+	request := func(origin string) {
+		r.GET("/v1/timeline").
+			SetHeader(map[string]string{"Origin": origin}).
+			Run(engine, func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 200, r.Code)
+			})
+	}
+
+	request("http://demoticker.org")
+	request("http://not-a-ticker.org")
+
+	// the domains are kept in memory until a ticker changes
+	storage.DB.Save(&model.Ticker{ID: 2, Active: true, Domain: "metrics-ticker.org"})
+	request("http://metrics-ticker.org")
+
+	assert.True(t, origins()["demoticker.org"])
+	assert.True(t, origins()["unknown"])
+	assert.False(t, origins()["not-a-ticker.org"])
+	assert.False(t, origins()["metrics-ticker.org"])
+
+	r.POST("/v1/admin/tickers/2/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(engine, func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	request("http://metrics-ticker.org")
+	assert.True(t, origins()["metrics-ticker.org"])
+}
+
+//origins returns the origin labels of the collected requests.
+func origins() map[string]bool {
+	families, _ := prometheus.DefaultGatherer.Gather()
+
+	origins := make(map[string]bool)
+	for _, family := range families {
+		if family.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "origin" {
+					origins[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	return origins
+}
@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestPublicRoutesHideInternalFields(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:              1,
+		Active:          true,
+		Domain:          "demoticker.org",
+		Aliases:         []string{"demoticker.onion"},
+		Title:           "Demoticker",
+		Hashtags:        []string{"#hashtag"},
+		RequireApproval: false,
+		Twitter:         model.Twitter{Active: true, Token: "twitter-token", Secret: "twitter-secret"},
+		Mastodon:        model.Mastodon{Active: true, Server: "https://mastodon.example", Token: "mastodon-token"},
+		Telegram:        model.Telegram{Active: true, ChatID: "telegram-chat"},
+	}
+
+	storage.DB.Save(&ticker)
+
+	message := model.NewMessage()
+	message.Ticker = 1
+	message.Text = "message"
+	message.Pinned = true
+	message.Author = 1
+	message.Reviewer = 2
+	message.RejectionReason = "rejection-reason"
+	message.Geometry = &model.Geometry{Type: model.GeometryTypePoint, Coordinates: []byte(`[13.4,52.5]`)}
+	message.Telegram = model.TelegramMessage{ChatID: "telegram-chat", MessageID: 1}
+	storage.DB.Save(message)
+
+	forbidden := []string{
+		"twitter-token", "twitter-secret", "mastodon-token", "telegram-chat", "mastodon.example",
+		"rejection-reason", `"author":1`, `"reviewer"`, `"draft"`, `"scheduled"`, `"deliveries"`, `"ticker":1`,
+		`"require_approval"`, `"prepend_time"`, `"hashtags"`, `"connected"`, `"domain"`, `"aliases"`, `"active"`,
+	}
+
+	routes := []string{
+		"/v1/init",
+		"/v1/timeline",
+		"/v1/timeline/geojson",
+		"/v1/feed.rss",
+		"/v1/feed.atom",
+		"/v1/feed.json",
+	}
+
+	for _, route := range routes {
+		r.GET(route).
+			SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 200, r.Code, route)
+
+				body := r.Body.String()
+				assert.Contains(t, body, "message", route)
+				for _, s := range forbidden {
+					assert.NotContains(t, body, s, route)
+				}
+			})
+	}
+}
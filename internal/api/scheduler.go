@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/asdine/storm"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/systemli/ticker/internal/hub"
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+//RunScheduler publishes the due scheduled messages in the given interval until the context is done.
+//The scheduled messages are stored in the database, so they survive restarts.
+func RunScheduler(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := PublishScheduledMessages(); err != nil {
+				log.WithError(err).Error("could not publish scheduled messages")
+			}
+		}
+	}
+}
+
+//PublishScheduledMessages publishes all scheduled messages which are due.
+func PublishScheduledMessages() error {
+	messages, err := FindDueScheduledMessages()
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		var ticker Ticker
+		err := DB.One("ID", message.Ticker, &ticker)
+		if err != nil {
+			log.WithError(err).WithField("message", message.ID).Error("could not find ticker for scheduled message")
+			continue
+		}
+
+		// the message may have been changed, unscheduled or deleted since it was loaded
+		err = publishStoredMessage(ticker, &message, func(stored *Message) bool {
+			return stored.Scheduled && !stored.PublishAt.After(time.Now())
+		})
+		if err == errMessageChanged || err == storm.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			log.WithError(err).WithField("message", message.ID).Error("could not publish scheduled message")
+		}
+	}
+
+	return nil
+}
+
+//errMessageChanged is returned when a message can't be published anymore, because it was changed in the meantime.
+var errMessageChanged = errors.New("message was changed")
+
+//publishStoredMessage publishes a scheduled or approved message. The message is reloaded in the transaction and
+//prepare decides whether the stored version can still be published. The message is stored with a new ID,
+//so clients which poll the timeline for messages after the latest known ID don't miss it.
+func publishStoredMessage(ticker Ticker, message *Message, prepare func(stored *Message) bool) error {
+	tx, err := DB.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stored Message
+	err = tx.One("ID", message.ID, &stored)
+	if err != nil {
+		return err
+	}
+	if !prepare(&stored) {
+		return errMessageChanged
+	}
+
+	err = tx.DeleteStruct(&stored)
+	if err != nil {
+		return err
+	}
+
+	*message = stored
+	message.ID = 0
+	message.Scheduled = false
+	message.CreationDate = time.Now()
+	appendHashtags(ticker, message)
+
+	err = tx.Save(message)
+	if err != nil {
+		return err
+	}
+
+	var revisions []MessageRevision
+	err = tx.Find("Message", stored.ID, &revisions)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	for _, revision := range revisions {
+		revision.Message = message.ID
+		err = tx.Save(&revision)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	// editors replace the stored message with the published one, the timeline never contained it
+	hub.Admin.Publish(hub.Event{Type: hub.MessageDeleted, Ticker: stored.Ticker, Data: NewMessageResponse(stored)})
+
+	distributeMessage(ticker, message)
+
+	return nil
+}
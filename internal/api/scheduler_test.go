@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestScheduledMessages(t *testing.T) {
+	r := setup()
+
+	fb := &fakeBridge{}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	ticker := model.Ticker{
+		ID:       1,
+		Active:   true,
+		Domain:   "demoticker.org",
+		Hashtags: []string{"#ticker"},
+	}
+
+	storage.DB.Save(&ticker)
+
+	publishAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(fmt.Sprintf(`{"text":"scheduled","publish_at":"%s"}`, publishAt)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.True(t, jres.Data["message"].Scheduled)
+			assert.Equal(t, publishAt, jres.Data["message"].PublishAt.UTC().Format(time.RFC3339))
+			assert.Equal(t, "scheduled", jres.Data["message"].Text)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"immediately"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	assert.Equal(t, []string{"immediately #ticker"}, fb.updated)
+	assert.Equal(t, []string{"immediately #ticker"}, timeline(t, r))
+
+	// not due yet
+	err := api.PublishScheduledMessages()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(fb.updated))
+
+	var message model.Message
+	storage.DB.One("ID", 1, &message)
+	message.PublishAt = time.Now().Add(-time.Second)
+	storage.DB.Save(&message)
+
+	err = api.PublishScheduledMessages()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"immediately #ticker", "scheduled #ticker"}, fb.updated)
+	assert.Equal(t, []string{"scheduled #ticker", "immediately #ticker"}, timeline(t, r))
+
+	// the published message gets a new id, so clients polling with after don't miss it
+	var published model.Message
+	err = storage.DB.One("ID", 3, &published)
+	assert.Nil(t, err)
+	assert.False(t, published.Scheduled)
+	assert.NotNil(t, storage.DB.One("ID", 1, &message))
+}
+
+func timeline(t *testing.T, r *gofight.RequestConfig) []string {
+	var texts []string
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.PublicMessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, message := range jres.Data["messages"] {
+				texts = append(texts, message.Text)
+			}
+		})
+
+	return texts
+}
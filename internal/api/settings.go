@@ -26,6 +26,11 @@ func GetSettingHandler(c *gin.Context) {
 		return
 	}
 
+	if c.Param("name") == SettingEnforceTwoFactor {
+		c.JSON(http.StatusOK, NewJSONSuccessResponse("setting", NewSettingResponse(GetEnforceTwoFactor())))
+		return
+	}
+
 	setting, err := FindSetting(c.Param("name"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorSettingNotFound))
@@ -62,6 +67,8 @@ func PutInactiveSettingsHandler(c *gin.Context) {
 		return
 	}
 
+	touchSettings()
+
 	c.JSON(http.StatusOK, NewJSONSuccessResponse("setting", NewSettingResponse(&setting)))
 }
 
@@ -94,6 +101,52 @@ func PutRefreshIntervalHandler(c *gin.Context) {
 		return
 	}
 
+	touchSettings()
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("setting", NewSettingResponse(&setting)))
+}
+
+//PutEnforceTwoFactorHandler updates enforce_two_factor
+func PutEnforceTwoFactorHandler(c *gin.Context) {
+	if !IsAdmin(c) {
+		c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+		return
+	}
+
+	var payload struct {
+		EnforceTwoFactor bool `json:"enforce_two_factor"`
+	}
+	err := c.Bind(&payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	me, err := Me(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		return
+	}
+
+	// the admin would lock out themself
+	if payload.EnforceTwoFactor && !me.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeTwoFactor, ErrorTwoFactorRequired))
+		return
+	}
+
+	var setting Setting
+	err = DB.One("Name", SettingEnforceTwoFactor, &setting)
+	if err != nil {
+		setting.Name = SettingEnforceTwoFactor
+	}
+
+	setting.Value = payload.EnforceTwoFactor
+	err = DB.Save(&setting)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
 	c.JSON(http.StatusOK, NewJSONSuccessResponse("setting", NewSettingResponse(&setting)))
 }
 
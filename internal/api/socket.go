@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/systemli/ticker/internal/hub"
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+var (
+	//SocketPingInterval is the interval for pings which keep idle connections open through proxies.
+	SocketPingInterval = 30 * time.Second
+	//SocketWriteTimeout is the maximum duration for sending a single event.
+	SocketWriteTimeout = 10 * time.Second
+
+	upgrader = websocket.Upgrader{
+		// the connection is authenticated by the token, not by cookies
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+)
+
+//GetTickerSocketHandler upgrades to a WebSocket connection which receives all changes of the ticker.
+//Browsers can't set headers for WebSocket connections, so the token may be passed as query parameter.
+func GetTickerSocketHandler(c *gin.Context) {
+	me, err := Me(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		return
+	}
+
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already responded with an error
+		return
+	}
+	defer conn.Close()
+
+	subscription := hub.Admin.Subscribe(ticker.ID)
+	defer subscription.Close()
+
+	// the client doesn't send events, but reading is needed to notice closed connections
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(SocketPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				closeSocket(conn, websocket.CloseTryAgainLater, "too slow")
+				return
+			}
+
+			err = conn.SetWriteDeadline(time.Now().Add(SocketWriteTimeout))
+			if err == nil {
+				err = conn.WriteJSON(event)
+			}
+			if err != nil {
+				log.WithError(err).WithField("ticker", ticker.ID).Debug("could not send event")
+				return
+			}
+
+			if event.Type == hub.TickerDeleted {
+				closeSocket(conn, websocket.CloseNormalClosure, "ticker deleted")
+				return
+			}
+			if event.Type == hub.TickerUsersUpdated && !stillMember(me.ID, ticker.ID) {
+				closeSocket(conn, websocket.ClosePolicyViolation, ErrorInsufficientPermissions)
+				return
+			}
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(SocketWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func closeSocket(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(SocketWriteTimeout))
+}
+
+func stillMember(userID, tickerID int) bool {
+	var user User
+	err := DB.One("ID", userID, &user)
+	if err != nil {
+		return false
+	}
+
+	return user.Can(tickerID, PermissionReadTicker)
+}
+
+//messageChanged informs the connected editors and, for public messages, the timeline streams.
+func messageChanged(event string, message Message) {
+	hub.Admin.Publish(hub.Event{Type: event, Ticker: message.Ticker, Data: NewMessageResponse(message)})
+
+	if isPublic(message) {
+		timelineChanged(event, message)
+	}
+}
+
+//timelineChanged informs the timeline streams about a change of the public timeline.
+func timelineChanged(event string, message Message) {
+	touchTicker(message.Ticker)
+	hub.Timeline.Publish(hub.Event{Type: event, Ticker: message.Ticker, Data: message})
+}
+
+//tickerChanged informs the connected editors about changed settings of the ticker.
+func tickerChanged(event string, ticker Ticker) {
+	touchTicker(ticker.ID)
+	hub.Admin.Publish(hub.Event{Type: event, Ticker: ticker.ID, Data: NewTickerResponse(&ticker)})
+}
+
+//usersChanged informs the connected editors about changed user assignments of the ticker.
+func usersChanged(tickerID int) {
+	users, err := FindUsersByTicker(Ticker{ID: tickerID})
+	if err != nil {
+		log.WithError(err).WithField("ticker", tickerID).Error("could not find users")
+		return
+	}
+
+	hub.Admin.Publish(hub.Event{Type: hub.TickerUsersUpdated, Ticker: tickerID, Data: NewUsersResponse(users)})
+}
@@ -0,0 +1,103 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/appleboy/gofight"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/hub"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestGetTickerSocketHandler(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	server := httptest.NewServer(api.API())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/admin/tickers/1/ws"
+
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+
+	_, res, err = websocket.DefaultDialer.Dial(url+"?token="+UserToken, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 403, res.StatusCode)
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+	user.Tickers = []int{1}
+	storage.DB.Save(&user)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+UserToken, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the subscription is registered after the upgrade
+	for i := 0; hub.Admin.Subscribers(1) == 0 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	var event struct {
+		Event  string                 `json:"event"`
+		Ticker int                    `json:"ticker"`
+		Data   map[string]interface{} `json:"data"`
+	}
+
+	err = conn.ReadJSON(&event)
+	assert.Nil(t, err)
+	assert.Equal(t, hub.MessageCreated, event.Event)
+	assert.Equal(t, 1, event.Ticker)
+	assert.Equal(t, "message", event.Data["text"])
+
+	r.PUT("/v1/admin/tickers/1/reset").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	err = conn.ReadJSON(&event)
+	assert.Nil(t, err)
+	assert.Equal(t, hub.TickerReset, event.Event)
+	assert.Equal(t, false, event.Data["active"])
+
+	r.DELETE("/v1/admin/tickers/1/users/2").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	err = conn.ReadJSON(&event)
+	assert.Nil(t, err)
+	assert.Equal(t, hub.TickerUsersUpdated, event.Event)
+
+	// the removed user is disconnected
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/systemli/ticker/internal/hub"
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+//StreamKeepAlive is the interval for comments which keep idle streams open through proxies.
+var StreamKeepAlive = 30 * time.Second
+
+//GetTimelineStreamHandler streams new, edited and deleted messages of a ticker as Server-Sent Events.
+//Clients resume with the Last-Event-ID header (or the last_event_id query parameter) after a reconnect.
+func GetTimelineStreamHandler(c *gin.Context) {
+	domain, err := GetDomain(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	ticker, err := FindTicker(domain)
+	if err != nil || !ticker.Active {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var lastID int
+	if lastEventID != "" {
+		lastID, err = strconv.Atoi(lastEventID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, "Last-Event-ID: must be a message id"))
+			return
+		}
+	}
+
+	// subscribe before loading the missed messages, so nothing gets lost in between
+	subscription := hub.Timeline.Subscribe(ticker.ID)
+	defer subscription.Close()
+
+	var missed []Message
+	if lastID > 0 {
+		missed, err = FindByTickerAfter(ticker, lastID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, message := range missed {
+		writeEvent(c.Writer, hub.MessageCreated, message)
+		lastID = message.ID
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(StreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return false
+			}
+			message := event.Data.(Message)
+			// skip messages which were already sent from the storage
+			if event.Type == hub.MessageCreated && message.ID <= lastID {
+				return true
+			}
+			writeEvent(w, event.Type, message)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-c.Request.Context().Done():
+			return false
+		}
+
+		return true
+	})
+}
+
+//writeEvent writes the event in the Server-Sent Events format. Only new messages carry an id,
+//because the id is used to resume the stream.
+func writeEvent(w io.Writer, event string, message Message) {
+	data, err := json.Marshal(NewPublicMessageResponse(message))
+	if err != nil {
+		log.WithError(err).WithField("message", message.ID).Error("could not encode stream event")
+		return
+	}
+
+	if event == hub.MessageCreated {
+		fmt.Fprintf(w, "id: %d\n", message.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestGetTimelineStreamHandler(t *testing.T) {
+	r := setup()
+
+	r.GET("/v1/timeline/stream").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+		})
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	for _, text := range []string{"first", "second"} {
+		r.POST("/v1/admin/tickers/1/messages").
+			SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+			SetBody(`{"text":"`+text+`"}`).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 200, r.Code)
+			})
+	}
+
+	server := httptest.NewServer(api.API())
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/v1/timeline/stream", nil)
+	req.Header.Set("Origin", "http://www.demoticker.org/")
+	req.Header.Set("Last-Event-ID", "1")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(res.Body)
+
+	// resumed after the last event id
+	event := readEvent(t, events)
+	assert.Equal(t, "id: 2", event[0])
+	assert.Equal(t, "event: message.created", event[1])
+	assert.Contains(t, event[2], `"text":"second"`)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"third"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	event = readEvent(t, events)
+	assert.Equal(t, "id: 3", event[0])
+	assert.Contains(t, event[2], `"text":"third"`)
+
+	r.PUT("/v1/admin/tickers/1/messages/3").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"third!"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	event = readEvent(t, events)
+	assert.Equal(t, "event: message.updated", event[0])
+	assert.Contains(t, event[1], `"text":"third!"`)
+
+	r.DELETE("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	event = readEvent(t, events)
+	assert.Equal(t, "event: message.deleted", event[0])
+	assert.Contains(t, event[1], `"id":1,`)
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
@@ -11,9 +13,11 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/hub"
 	. "github.com/systemli/ticker/internal/model"
 	. "github.com/systemli/ticker/internal/storage"
 	"github.com/systemli/ticker/internal/util"
+	"github.com/systemli/ticker/internal/webhook"
 )
 
 //GetTickersHandler returns all Ticker with paging
@@ -40,68 +44,39 @@ func GetTickersHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("tickers", NewTickersResponse(tickers)))
+	// api tokens can be restricted to some of the tickers
+	if _, ok := CurrentAPIToken(c); ok {
+		var visible []*Ticker
+		for _, ticker := range tickers {
+			if Allowed(c, ticker.ID, PermissionReadTicker) {
+				visible = append(visible, ticker)
+			}
+		}
+		tickers = visible
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("tickers", tickersResponse(c, tickers)))
 }
 
 //GetTickerHandler returns a Ticker for the given id
 func GetTickerHandler(c *gin.Context) {
-	me, err := Me(c)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
-		return
-	}
-
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
-	}
-
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
-		return
-	}
-
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", NewTickerResponse(&ticker)))
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
 }
 
 //GetTickerUsersHandler returns Users for the given ticker
 func GetTickerUsersHandler(c *gin.Context) {
-	me, err := Me(c)
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
-		return
-	}
-
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
-		return
-	}
-
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
-	}
-
 	//TODO: Discuss need of Pagination
 	users, _ := FindUsersByTicker(ticker)
 
@@ -128,34 +103,16 @@ func PostTickerHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", NewTickerResponse(ticker)))
+	touchTicker(ticker.ID)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, ticker)))
 }
 
 //PutTickerHandler updates and returns a existing Ticker
 func PutTickerHandler(c *gin.Context) {
-	me, err := Me(c)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
-		return
-	}
-
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
-		return
-	}
-
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
-	}
-
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
@@ -171,39 +128,65 @@ func PutTickerHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", NewTickerResponse(&ticker)))
+	webhook.Dispatch(EventTickerUpdated, ticker.ID, NewTickerResponse(&ticker))
+	tickerChanged(hub.TickerUpdated, ticker)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
 }
 
 //PutTickerUsersHandler changes the allowed users for a ticker
 func PutTickerUsersHandler(c *gin.Context) {
-	me, err := Me(c)
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	var body struct {
+		Users []int  `json:"users" binding:"required"`
+		Role  string `json:"role"`
+	}
+
+	err = c.Bind(&body)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	if body.Role != "" {
+		err = ValidateRole(body.Role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
+	err = AddUsersToTicker(ticker, body.Users, body.Role)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
+	usersChanged(ticker.ID)
+
+	users, _ := FindUsersByTicker(ticker)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("users", NewUsersResponse(users)))
+}
+
+//
+func PutTickerTwitterHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
 	}
 
 	var body struct {
-		Users []int `json:"users" binding:"required"`
+		Active     bool   `json:"active,omitempty"`
+		Disconnect bool   `json:"disconnect"`
+		Token      string `json:"token,omitempty"`
+		Secret     string `json:"secret,omitempty"`
 	}
 
 	err = c.Bind(&body)
@@ -212,50 +195,115 @@ func PutTickerUsersHandler(c *gin.Context) {
 		return
 	}
 
-	err = AddUsersToTicker(ticker, body.Users)
+	if body.Disconnect {
+		ticker.Twitter.Token = ""
+		ticker.Twitter.Secret = ""
+		ticker.Twitter.Active = false
+		ticker.Twitter.User = twitter.User{}
+	} else {
+		if body.Token != "" {
+			ticker.Twitter.Token = body.Token
+		}
+		if body.Secret != "" {
+			ticker.Twitter.Secret = body.Secret
+		}
+		ticker.Twitter.Active = body.Active
+	}
+
+	tb, ok := bridge.Get(bridge.TwitterBridgeName).(*bridge.TwitterBridge)
+	if ok && ticker.Twitter.Connected() {
+		user, err := tb.User(ticker)
+		if err == nil {
+			ticker.Twitter.User = *user
+		}
+	}
+
+	err = DB.Save(&ticker)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	users, _ := FindUsersByTicker(ticker)
+	webhook.Dispatch(EventTickerUpdated, ticker.ID, NewTickerResponse(&ticker))
+	tickerChanged(hub.TickerUpdated, ticker)
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("users", NewUsersResponse(users)))
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
 }
 
-//
-func PutTickerTwitterHandler(c *gin.Context) {
-	me, err := Me(c)
+//PutTickerMastodonHandler connects or disconnects a Mastodon account
+func PutTickerMastodonHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	var body struct {
+		Active     bool   `json:"active,omitempty"`
+		Disconnect bool   `json:"disconnect"`
+		Server     string `json:"server,omitempty"`
+		Token      string `json:"token,omitempty"`
+	}
+
+	err = c.Bind(&body)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+	if body.Server != "" {
+		// the server is requested with the token of the ticker
+		u, err := url.Parse(body.Server)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, "Server: invalid url"))
 			return
 		}
 	}
 
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	if body.Disconnect {
+		ticker.Mastodon = Mastodon{}
+	} else {
+		if body.Server != "" {
+			ticker.Mastodon.Server = strings.TrimSuffix(body.Server, "/")
+		}
+		if body.Token != "" {
+			ticker.Mastodon.Token = body.Token
+		}
+		ticker.Mastodon.Active = body.Active
+	}
+
+	mb, ok := bridge.Get(bridge.MastodonBridgeName).(*bridge.MastodonBridge)
+	if ok && ticker.Mastodon.Connected() {
+		user, err := mb.User(ticker)
+		if err == nil {
+			ticker.Mastodon.User = *user
+		}
+	}
+
+	err = DB.Save(&ticker)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
+	webhook.Dispatch(EventTickerUpdated, ticker.ID, NewTickerResponse(&ticker))
+	tickerChanged(hub.TickerUpdated, ticker)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
+}
+
+//PutTickerTelegramHandler configures the Telegram chat for a ticker
+func PutTickerTelegramHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
 	var body struct {
 		Active     bool   `json:"active,omitempty"`
 		Disconnect bool   `json:"disconnect"`
-		Token      string `json:"token,omitempty"`
-		Secret     string `json:"secret,omitempty"`
+		ChatID     string `json:"chat_id,omitempty"`
 	}
 
 	err = c.Bind(&body)
@@ -265,24 +313,19 @@ func PutTickerTwitterHandler(c *gin.Context) {
 	}
 
 	if body.Disconnect {
-		ticker.Twitter.Token = ""
-		ticker.Twitter.Secret = ""
-		ticker.Twitter.Active = false
-		ticker.Twitter.User = twitter.User{}
+		ticker.Telegram = Telegram{}
 	} else {
-		if body.Token != "" {
-			ticker.Twitter.Token = body.Token
-		}
-		if body.Secret != "" {
-			ticker.Twitter.Secret = body.Secret
+		if body.ChatID != "" {
+			ticker.Telegram.ChatID = body.ChatID
 		}
-		ticker.Twitter.Active = body.Active
+		ticker.Telegram.Active = body.Active
 	}
 
-	if ticker.Twitter.Connected() {
-		user, err := bridge.Twitter.User(ticker)
+	tb, ok := bridge.Get(bridge.TelegramBridgeName).(*bridge.TelegramBridge)
+	if ok && ticker.Telegram.Connected() {
+		chat, err := tb.Chat(ticker)
 		if err == nil {
-			ticker.Twitter.User = *user
+			ticker.Telegram.ChatTitle = chat.Title
 		}
 	}
 
@@ -292,7 +335,10 @@ func PutTickerTwitterHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", NewTickerResponse(&ticker)))
+	webhook.Dispatch(EventTickerUpdated, ticker.ID, NewTickerResponse(&ticker))
+	tickerChanged(hub.TickerUpdated, ticker)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
 }
 
 //DeleteTickerHandler deletes a existing Ticker
@@ -302,21 +348,24 @@ func DeleteTickerHandler(c *gin.Context) {
 		return
 	}
 
-	var ticker Ticker
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
-		return
+	DB.Select(q.Eq("Ticker", ticker.ID)).Delete(new(Message))
+	DeleteDeliveries("Ticker", ticker.ID)
+	DeleteRevisions("Ticker", ticker.ID)
+	DeleteUploadsByTicker(ticker.ID)
+	DB.Select(q.Eq("ID", ticker.ID)).Delete(new(Ticker))
+
+	webhooks, _ := FindWebhooksByTicker(ticker.ID)
+	for _, w := range webhooks {
+		DeleteWebhook(w)
 	}
 
-	DB.Select(q.Eq("Ticker", tickerID)).Delete(new(Message))
-	DB.Select(q.Eq("ID", tickerID)).Delete(new(Ticker))
+	tickerChanged(hub.TickerDeleted, ticker)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":   nil,
@@ -327,32 +376,12 @@ func DeleteTickerHandler(c *gin.Context) {
 
 //DeleteTickerUserHandler removes ticker credentials for a user
 func DeleteTickerUserHandler(c *gin.Context) {
-	me, err := Me(c)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
-		return
-	}
-
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
-		return
-	}
-
-	var ticker Ticker
-	err = DB.One("ID", tickerID, &ticker)
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
-	if !me.IsSuperAdmin {
-		if !contains(me.Tickers, tickerID) {
-			c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
-			return
-		}
-	}
-
 	userID, err := strconv.Atoi(c.Param("userID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
@@ -372,6 +401,8 @@ func DeleteTickerUserHandler(c *gin.Context) {
 		return
 	}
 
+	usersChanged(ticker.ID)
+
 	users, _ := FindUsersByTicker(ticker)
 
 	c.JSON(http.StatusOK, NewJSONSuccessResponse("users", NewUsersResponse(users)))
@@ -383,21 +414,17 @@ func ResetTickerHandler(c *gin.Context) {
 		return
 	}
 
-	var ticker Ticker
-	tickerID, err := strconv.Atoi(c.Param("tickerID"))
+	ticker, err := CurrentTicker(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
-		return
-	}
-
-	err = DB.One("ID", tickerID, &ticker)
-	if err != nil {
-		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, err.Error()))
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
 		return
 	}
 
 	//Delete all messages for ticker
-	DB.Select(q.Eq("Ticker", tickerID)).Delete(new(Message))
+	DB.Select(q.Eq("Ticker", ticker.ID)).Delete(new(Message))
+	DeleteDeliveries("Ticker", ticker.ID)
+	DeleteRevisions("Ticker", ticker.ID)
+	DeleteUploadsByTicker(ticker.ID)
 
 	ticker.Reset()
 
@@ -407,7 +434,32 @@ func ResetTickerHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", NewTickerResponse(&ticker)))
+	webhook.Dispatch(EventTickerReset, ticker.ID, NewTickerResponse(&ticker))
+	tickerChanged(hub.TickerReset, ticker)
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("ticker", tickerResponse(c, &ticker)))
+}
+
+//tickerResponse returns the ticker with the role and the permissions of the current user.
+func tickerResponse(c *gin.Context, ticker *Ticker) *TickerResponse {
+	tr := NewTickerResponse(ticker)
+
+	me, err := Me(c)
+	if err == nil {
+		tr.Role = me.TickerRole(ticker.ID)
+		tr.Permissions = Permissions(c, ticker.ID)
+	}
+
+	return tr
+}
+
+func tickersResponse(c *gin.Context, tickers []*Ticker) []*TickerResponse {
+	var tr []*TickerResponse
+	for _, ticker := range tickers {
+		tr = append(tr, tickerResponse(c, ticker))
+	}
+
+	return tr
 }
 
 func contains(s []int, e int) bool {
@@ -421,13 +473,15 @@ func contains(s []int, e int) bool {
 
 func updateTicker(t *Ticker, c *gin.Context) error {
 	var body struct {
-		Domain      string   `json:"domain" binding:"required"`
-		Title       string   `json:"title" binding:"required"`
-		Description string   `json:"description" binding:"required"`
-		Active      bool     `json:"active"`
-		PrependTime bool     `json:"prepend_time"`
-		Hashtags    []string `json:"hashtags"`
-		Information struct {
+		Domain          string   `json:"domain" binding:"required"`
+		Aliases         []string `json:"aliases"`
+		Title           string   `json:"title" binding:"required"`
+		Description     string   `json:"description" binding:"required"`
+		Active          bool     `json:"active"`
+		PrependTime     bool     `json:"prepend_time"`
+		Hashtags        []string `json:"hashtags"`
+		RequireApproval bool     `json:"require_approval"`
+		Information     struct {
 			Author   string `json:"author"`
 			URL      string `json:"url"`
 			Email    string `json:"email"`
@@ -485,12 +539,20 @@ func updateTicker(t *Ticker, c *gin.Context) error {
 	if err != nil == true {
 		return err
 	}
-	t.Domain = body.Domain
+
+	aliases, err := tickerAliases(t, body.Domain, body.Aliases)
+	if err != nil {
+		return err
+	}
+
+	t.Domain = NormalizeDomain(body.Domain)
+	t.Aliases = aliases
 	t.Title = body.Title
 	t.Description = body.Description
 	t.Active = body.Active
 	t.PrependTime = body.PrependTime
 	t.Hashtags = body.Hashtags
+	t.RequireApproval = body.RequireApproval
 	t.Information.Author = body.Information.Author
 	t.Information.URL = body.Information.URL
 	t.Information.Email = body.Information.Email
@@ -499,3 +561,42 @@ func updateTicker(t *Ticker, c *gin.Context) error {
 
 	return nil
 }
+
+//tickerAliases normalizes the aliases and checks that neither the primary domain nor an alias is used by another ticker.
+func tickerAliases(t *Ticker, domain string, aliases []string) ([]string, error) {
+	domain = NormalizeDomain(domain)
+	domains := []string{domain}
+	normalized := []string{}
+
+	for _, alias := range aliases {
+		alias = NormalizeDomain(alias)
+		v := util.Validator(alias)
+		if !v.Required().MinLength(5).Check() {
+			return nil, errors.New("Aliases: " + v.E)
+		}
+		if alias == domain || stringContains(normalized, alias) {
+			continue
+		}
+
+		normalized = append(normalized, alias)
+		domains = append(domains, alias)
+	}
+
+	for _, d := range domains {
+		other, err := FindTicker(d)
+		if err == nil && other.ID != t.ID {
+			return nil, errors.New("Domain: " + d + " is already used by another ticker")
+		}
+	}
+
+	return normalized, nil
+}
+
+func stringContains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
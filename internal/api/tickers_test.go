@@ -3,6 +3,8 @@ package api_test
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/bridge"
 	"github.com/systemli/ticker/internal/model"
 	"github.com/systemli/ticker/internal/storage"
 )
@@ -44,7 +47,7 @@ func TestGetTickerHandler(t *testing.T) {
 		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
 		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
 			assert.Equal(t, 404, r.Code)
-			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"not found"}}`, strings.TrimSpace(r.Body.String()))
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"ticker not found"}}`, strings.TrimSpace(r.Body.String()))
 		})
 
 	r.GET("/v1/admin/tickers/1").
@@ -116,6 +119,76 @@ func TestPostTickerHandler(t *testing.T) {
 		})
 }
 
+func TestPostTickerHandlerAliases(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:      1,
+		Domain:  "demoticker.org",
+		Aliases: []string{"demoticker.onion"},
+	}
+
+	storage.DB.Save(&ticker)
+
+	body := func(domain, aliases string) string {
+		return `{
+			"title": "Ticker",
+			"domain": "` + domain + `",
+			"aliases": ` + aliases + `,
+			"description": "Beschreibung",
+			"active": true,
+			"information": {
+				"author": "systemli",
+				"url": "https://www.systemli.org",
+				"email": "admin@systemli.org",
+				"twitter": "systemli",
+				"facebook": "systemli"
+			}
+		}`
+	}
+
+	r.POST("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(body("prozessticker.org", `["DemoTicker.onion"]`)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Contains(t, r.Body.String(), "Domain: demoticker.onion is already used by another ticker")
+		})
+
+	r.POST("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(body("www.demoticker.org", `[]`)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Contains(t, r.Body.String(), "Domain: demoticker.org is already used by another ticker")
+		})
+
+	r.POST("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(body("prozessticker.org", `["www.prozessticker.onion", "prozessticker.onion", "prozessticker.org"]`)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.TickerResponse `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "prozessticker.org", jres.Data["ticker"].Domain)
+			assert.Equal(t, []string{"prozessticker.onion"}, jres.Data["ticker"].Aliases)
+		})
+
+	r.GET("/v1/init").
+		SetHeader(map[string]string{"Origin": "http://prozessticker.onion"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Contains(t, r.Body.String(), `"title":"Ticker"`)
+		})
+}
+
 func TestPutTickerHandler(t *testing.T) {
 	r := setup()
 
@@ -481,10 +554,94 @@ func TestDeleteTickerUserHandler(t *testing.T) {
 
 }
 
+func TestPutTickerMastodonHandler(t *testing.T) {
+	r := setup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/accounts/verify_credentials", r.URL.Path)
+		w.Write([]byte(`{"id":"1","username":"ticker","display_name":"Ticker","url":"https://mastodon.example/@ticker","avatar":"https://mastodon.example/avatar.png"}`))
+	}))
+	defer server.Close()
+
+	bridge.Register(bridge.MastodonBridgeName, bridge.NewMastodonBridge())
+	defer bridge.Unregister(bridge.MastodonBridgeName)
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	body := fmt.Sprintf(`{"active":true,"server":"%s/","token":"token"}`, server.URL)
+
+	for _, invalid := range []string{"mastodon.example", "file:///etc/passwd", "gopher://mastodon.example", "https://"} {
+		r.PUT("/v1/admin/tickers/1/mastodon").
+			SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+			SetBody(fmt.Sprintf(`{"active":true,"server":"%s","token":"token"}`, invalid)).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 400, r.Code)
+				assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Server: invalid url"}}`, strings.TrimSpace(r.Body.String()))
+			})
+	}
+
+	r.PUT("/v1/admin/tickers/1/mastodon").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.PUT("/v1/admin/tickers/1/mastodon").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.TickerResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mastodon := jres.Data["ticker"].Mastodon
+			assert.True(t, mastodon.Active)
+			assert.True(t, mastodon.Connected)
+			assert.Equal(t, server.URL, mastodon.Server)
+			assert.Equal(t, "ticker", mastodon.ScreenName)
+			assert.Equal(t, "Ticker", mastodon.Name)
+			assert.Equal(t, "https://mastodon.example/avatar.png", mastodon.ImageURL)
+		})
+
+	r.PUT("/v1/admin/tickers/1/mastodon").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"disconnect":true}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.TickerResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.False(t, jres.Data["ticker"].Mastodon.Active)
+			assert.False(t, jres.Data["ticker"].Mastodon.Connected)
+		})
+}
+
 func setup() *gofight.RequestConfig {
 	gin.SetMode(gin.TestMode)
 
 	model.Config = model.NewConfig()
+	// the tests change the storage directly
+	api.CacheTTL = 0
 
 	if storage.DB == nil {
 		storage.DB = storage.OpenDB("ticker_test.db")
@@ -493,6 +650,13 @@ func setup() *gofight.RequestConfig {
 	storage.DB.Drop("Message")
 	storage.DB.Drop("User")
 	storage.DB.Drop("Setting")
+	storage.DB.Drop("Webhook")
+	storage.DB.Drop("WebhookDelivery")
+	storage.DB.Drop("PendingWebhookDelivery")
+	storage.DB.Drop("BridgeDelivery")
+	storage.DB.Drop("Upload")
+	storage.DB.Drop("MessageRevision")
+	storage.DB.Drop("APIToken")
 
 	admin, _ := model.NewUser("admin@systemli.org", "password")
 	admin.IsSuperAdmin = true
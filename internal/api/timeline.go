@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,13 @@ import (
 //GetTimelineHandler returns the public timeline for a ticker.
 func GetTimelineHandler(c *gin.Context) {
 	domain, err := GetDomain(c)
+	pagination := NewPagination(c)
+	key := fmt.Sprintf("timeline?limit=%d&before=%d&after=%d", pagination.GetLimit(), pagination.GetBefore(), pagination.GetAfter())
+	if err == nil && serveCached(c, domain, key) {
+		return
+	}
+	version := cacheVersion()
+
 	if err != nil {
 		c.JSON(http.StatusOK, JSONResponse{
 			Data:   map[string]interface{}{"messages": nil},
@@ -38,13 +46,60 @@ func GetTimelineHandler(c *gin.Context) {
 		return
 	}
 
-	pagination := NewPagination(c)
+	modified := lastModified(ticker.ID)
 	messages, err := FindByTicker(ticker, pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
 
-	c.JSON(http.StatusOK, JSONResponse{
-		Data:   map[string]interface{}{"messages": NewMessagesResponse(messages)},
+	pinned, err := FindPinnedByTicker(ticker)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	cachedJSON(c, domain, key, version, ticker.ID, modified, JSONResponse{
+		Data:   map[string]interface{}{"messages": NewPublicMessagesResponse(messages), "pinned": NewPublicMessagesResponse(pinned)},
 		Status: ResponseSuccess,
 		Error:  nil,
 	})
-	return
+}
+
+//GetTimelineGeoJSONHandler returns the located messages of a ticker as GeoJSON FeatureCollection.
+func GetTimelineGeoJSONHandler(c *gin.Context) {
+	domain, err := GetDomain(c)
+	if err != nil {
+		c.JSON(http.StatusOK, JSONResponse{
+			Data:   map[string]interface{}{"features": nil},
+			Status: ResponseError,
+			Error: map[string]interface{}{
+				"code":    ErrorCodeDefault,
+				"message": `Could not find a ticker.`,
+			},
+		})
+		return
+	}
+
+	ticker, err := FindTicker(domain)
+	if err != nil {
+		c.JSON(http.StatusOK, JSONResponse{
+			Data:   map[string]interface{}{"features": nil},
+			Status: ResponseError,
+			Error: map[string]interface{}{
+				"code":    ErrorCodeDefault,
+				"message": `Could not find a ticker.`,
+			},
+		})
+		return
+	}
+
+	messages, err := FindGeoMessagesByTicker(ticker)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "application/geo+json; charset=utf-8")
+	c.JSON(http.StatusOK, NewFeatureCollection(messages))
 }
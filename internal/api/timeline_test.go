@@ -0,0 +1,308 @@
+package api_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestGetTimelineGeoJSONHandler(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message","geometry":{"type":"LineString","coordinates":[[13.4,52.5],[13.5,52.5]]}}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Geometry: unsupported type LineString"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"located","geometry":{"type":"Point","coordinates":[13.4,52.5]}}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, model.GeometryTypePoint, jres.Data["message"].Geometry.Type)
+			assert.Equal(t, `[13.4,52.5]`, string(jres.Data["message"].Geometry.Coordinates))
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"without location"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.GET("/v1/timeline/geojson").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Equal(t, "application/geo+json; charset=utf-8", r.HeaderMap.Get("Content-Type"))
+
+			var fc model.FeatureCollection
+			err := json.Unmarshal(r.Body.Bytes(), &fc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "FeatureCollection", fc.Type)
+			assert.Equal(t, 1, len(fc.Features))
+			assert.Equal(t, 1, fc.Features[0].ID)
+			assert.Equal(t, "located", fc.Features[0].Properties["text"])
+		})
+}
+
+func TestGetTimelineHandlerPinned(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message","severity":"critical"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Severity: must be one of info, warning, alert"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"road closed","severity":"alert","pinned":true}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.Equal(t, model.SeverityAlert, message.Severity)
+			assert.True(t, message.Pinned)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.Equal(t, model.SeverityInfo, message.Severity)
+			assert.False(t, message.Pinned)
+		})
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.PublicMessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, len(jres.Data["messages"]))
+			assert.Equal(t, 1, len(jres.Data["pinned"]))
+			assert.Equal(t, "road closed", jres.Data["pinned"][0].Text)
+		})
+
+	r.PUT("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"road closed","pinned":false}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			message := messageResponse(t, r)
+			assert.False(t, message.Pinned)
+			assert.Nil(t, message.EditedAt)
+		})
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.PublicMessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 0, len(jres.Data["pinned"]))
+		})
+}
+
+func TestGetTimelineHandlerConditional(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	var etag, modified string
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			etag = r.HeaderMap.Get("ETag")
+			modified = r.HeaderMap.Get("Last-Modified")
+			assert.NotEmpty(t, etag)
+			assert.NotEmpty(t, modified)
+		})
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/", "If-None-Match": etag}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 304, r.Code)
+			assert.Empty(t, r.Body.String())
+		})
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/", "If-Modified-Since": modified}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 304, r.Code)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.GET("/v1/timeline").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/", "If-None-Match": etag}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.NotEqual(t, etag, r.HeaderMap.Get("ETag"))
+		})
+
+	r.GET("/v1/init").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/"}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			etag = r.HeaderMap.Get("ETag")
+		})
+
+	r.GET("/v1/init").
+		SetHeader(map[string]string{"Origin": "http://www.demoticker.org/", "If-None-Match": etag}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 304, r.Code)
+		})
+}
+
+func TestGetTimelineHandlerCache(t *testing.T) {
+	r := setup()
+
+	api.CacheTTL = time.Minute
+	defer func() { api.CacheTTL = 0 }()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+		Domain: "demoticker.org",
+	}
+
+	storage.DB.Save(&ticker)
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"first"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	assert.Equal(t, []string{"first"}, timeline(t, r))
+
+	// changes outside of the api are not visible until the cache expires
+	message := model.NewMessage()
+	message.Ticker = 1
+	message.Text = "unnoticed"
+	storage.DB.Save(message)
+
+	assert.Equal(t, []string{"first"}, timeline(t, r))
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"second"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	assert.Equal(t, []string{"second", "unnoticed", "first"}, timeline(t, r))
+}
+
+func TestGetTimelineHandlerSlug(t *testing.T) {
+	r := setup()
+
+	ticker := model.Ticker{
+		ID:      1,
+		Active:  true,
+		Domain:  "demoticker.org",
+		Aliases: []string{"demoticker.onion"},
+	}
+
+	storage.DB.Save(&ticker)
+
+	message := model.NewMessage()
+	message.Ticker = 1
+	message.Text = "message"
+	storage.DB.Save(message)
+
+	for _, slug := range []string{"demoticker.org", "demoticker.onion"} {
+		r.GET("/v1/tickers/"+slug+"/timeline").
+			SetHeader(map[string]string{"Origin": "http://prozessticker.org/"}).
+			Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+				assert.Equal(t, 200, r.Code)
+				assert.Contains(t, r.Body.String(), `"text":"message"`)
+			})
+	}
+
+	r.GET("/v1/tickers/prozessticker.org/init").
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Contains(t, r.Body.String(), `"ticker":null`)
+		})
+}
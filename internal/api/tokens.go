@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+//GetAPITokensHandler returns all API tokens of the user
+func GetAPITokensHandler(c *gin.Context) {
+	userID, ok := tokenUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := FindAPITokensByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("tokens", NewAPITokensResponse(tokens)))
+}
+
+//PostAPITokenHandler creates a new API token and returns it once with the plaintext token
+func PostAPITokenHandler(c *gin.Context) {
+	userID, ok := tokenUser(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name    string   `json:"name" binding:"required"`
+		Scopes  []string `json:"scopes"`
+		Tickers []int    `json:"tickers"`
+	}
+
+	err := c.Bind(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	err = ValidateScopes(body.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	apiToken, token := NewAPIToken(userID, body.Name)
+	if body.Scopes != nil {
+		apiToken.Scopes = body.Scopes
+	}
+	if body.Tickers != nil {
+		apiToken.Tickers = body.Tickers
+	}
+
+	err = DB.Save(apiToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	response := NewAPITokenResponse(*apiToken)
+	response.Token = token
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("token", response))
+}
+
+//DeleteAPITokenHandler revokes the API token
+func DeleteAPITokenHandler(c *gin.Context) {
+	userID, ok := tokenUser(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.Atoi(c.Param("tokenID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	var apiToken APIToken
+	err = DB.One("ID", tokenID, &apiToken)
+	if err != nil || apiToken.User != userID {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorAPITokenNotFound))
+		return
+	}
+
+	err = DB.DeleteStruct(&apiToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   nil,
+		"status": ResponseSuccess,
+		"error":  nil,
+	})
+}
+
+//tokenUser returns the user whose API tokens are managed. Users manage their own tokens, admins the tokens of
+//everyone. API tokens can't be used to manage API tokens.
+func tokenUser(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return 0, false
+	}
+
+	me, err := Me(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		return 0, false
+	}
+
+	if _, ok := CurrentAPIToken(c); ok || (!IsAdmin(c) && me.ID != userID) {
+		c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+		return 0, false
+	}
+
+	_, err = FindUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorUserNotFound))
+		return 0, false
+	}
+
+	return userID, true
+}
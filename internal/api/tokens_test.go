@@ -0,0 +1,155 @@
+package api_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestAPITokens(t *testing.T) {
+	r := setup()
+
+	storage.DB.Save(&model.Ticker{ID: 1, Active: true, Domain: "demoticker.org"})
+	storage.DB.Save(&model.Ticker{ID: 2, Active: true, Domain: "another.org"})
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+	user.Tickers = []int{1, 2}
+	storage.DB.Save(&user)
+
+	tokens := "/v1/admin/users/" + strconv.Itoa(user.ID) + "/tokens"
+
+	r.POST(tokens).
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"name":"bot","scopes":["admin"]}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+		})
+
+	// users can't manage the tokens of others
+	r.GET("/v1/admin/users/1/tokens").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	var created model.APITokenResponse
+	r.POST(tokens).
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"name":"bot","scopes":["messages:write"],"tickers":[1]}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.APITokenResponse `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created = jres.Data["token"]
+			assert.True(t, model.IsAPIToken(created.Token))
+			assert.Equal(t, []string{model.ScopeMessagesWrite}, created.Scopes)
+			assert.Nil(t, created.LastUsedAt)
+		})
+
+	bearer := map[string]string{"Authorization": "Bearer " + created.Token}
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(bearer).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.False(t, messageResponse(t, r).Draft)
+		})
+
+	// the token is restricted to the ticker and the scopes
+	r.POST("/v1/admin/tickers/2/messages").
+		SetHeader(bearer).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.PUT("/v1/admin/tickers/1/telegram").
+		SetHeader(bearer).
+		SetBody(`{"active":false}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers").
+		SetHeader(bearer).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.TickerResponse `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Len(t, jres.Data["tickers"], 1)
+			assert.NotContains(t, jres.Data["tickers"][0].Permissions, model.PermissionWriteTicker)
+		})
+
+	// api tokens can't be refreshed like sessions
+	r.GET("/v1/admin/refresh_token").
+		SetHeader(bearer).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 401, r.Code)
+		})
+
+	// api tokens can't manage api tokens
+	r.GET(tokens).
+		SetHeader(bearer).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.GET(tokens).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.NotContains(t, r.Body.String(), created.Token)
+
+			var jres struct {
+				Data map[string][]model.APITokenResponse `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Len(t, jres.Data["tokens"], 1)
+			assert.NotNil(t, jres.Data["tokens"][0].LastUsedAt)
+		})
+
+	r.DELETE(tokens+"/"+strconv.Itoa(created.ID)).
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.GET("/v1/admin/tickers").
+		SetHeader(bearer).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 401, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1002,"message":"invalid api token"}}`, r.Body.String())
+		})
+
+	r.DELETE(tokens+"/"+strconv.Itoa(created.ID)).
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+		})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+//PostTOTPHandler creates a new secret for the two-factor authentication of the current user
+func PostTOTPHandler(c *gin.Context) {
+	me, ok := totpUser(c)
+	if !ok {
+		return
+	}
+
+	secret, err := me.EnrollTOTP()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	err = DB.Save(&me)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("totp", gin.H{
+		"secret": secret,
+		"url":    me.TOTPURL(secret),
+	}))
+}
+
+//PostTOTPEnableHandler enables the two-factor authentication of the current user and returns the recovery codes
+func PostTOTPEnableHandler(c *gin.Context) {
+	me, ok := totpUser(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	err := c.Bind(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	codes, err := me.EnableTOTP(body.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeTwoFactor, err.Error()))
+		return
+	}
+
+	err = DB.Save(&me)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("recovery_codes", codes))
+}
+
+//PostTOTPDisableHandler disables the two-factor authentication of the current user after checking the second factor
+func PostTOTPDisableHandler(c *gin.Context) {
+	me, ok := totpUser(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	err := c.Bind(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	if !me.VerifySecondFactor(body.Code) {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeTwoFactor, ErrorInvalidOTP))
+		return
+	}
+
+	me.DisableTOTP()
+	err = DB.Save(&me)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("user", NewUserResponse(me)))
+}
+
+//DeleteUserTOTPHandler resets the two-factor authentication of a user who lost the second factor
+func DeleteUserTOTPHandler(c *gin.Context) {
+	if !IsAdmin(c) {
+		c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	var user User
+	err = DB.One("ID", userID, &user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorUserNotFound))
+		return
+	}
+
+	user.DisableTOTP()
+	err = DB.Save(&user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("user", NewUserResponse(user)))
+}
+
+//totpUser returns the current user. The two-factor authentication can't be changed with API tokens.
+func totpUser(c *gin.Context) (User, bool) {
+	me, err := Me(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, ErrorUserNotFound))
+		return me, false
+	}
+
+	if _, ok := CurrentAPIToken(c); ok {
+		c.JSON(http.StatusForbidden, NewJSONErrorResponse(ErrorCodeInsufficientPermissions, ErrorInsufficientPermissions))
+		return me, false
+	}
+
+	return me, true
+}
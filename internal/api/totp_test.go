@@ -0,0 +1,181 @@
+package api_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/totp"
+)
+
+func TestTOTP(t *testing.T) {
+	r := setup()
+
+	var user model.User
+	storage.DB.One("Email", "louis@systemli.org", &user)
+
+	var secret string
+	r.POST("/v1/admin/totp").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]map[string]string `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			secret = jres.Data["totp"]["secret"]
+			assert.Contains(t, jres.Data["totp"]["url"], "otpauth://totp/")
+		})
+
+	r.POST("/v1/admin/totp/enable").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"code":"000000"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+		})
+
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(secret, step)
+
+	var recoveryCodes []string
+	r.POST("/v1/admin/totp/enable").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"code":"`+code+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]string `json:"data"`
+			}
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			recoveryCodes = jres.Data["recovery_codes"]
+			assert.Len(t, recoveryCodes, model.RecoveryCodeCount)
+		})
+
+	r.POST("/v1/admin/login").
+		SetBody(`{"username":"louis@systemli.org","password":"password"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 401, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1004,"message":"one-time password required"}}`, r.Body.String())
+		})
+
+	// used one-time passwords are rejected
+	r.POST("/v1/admin/login").
+		SetBody(`{"username":"louis@systemli.org","password":"password","otp":"`+code+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 401, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1004,"message":"invalid one-time password"}}`, r.Body.String())
+		})
+
+	next, _ := totp.Code(secret, step+1)
+	r.POST("/v1/admin/login").
+		SetBody(`{"username":"louis@systemli.org","password":"password","otp":"`+next+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Contains(t, r.Body.String(), "token")
+		})
+
+	r.POST("/v1/admin/login").
+		SetBody(`{"username":"louis@systemli.org","password":"password","otp":"`+recoveryCodes[0]+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.POST("/v1/admin/login").
+		SetBody(`{"username":"louis@systemli.org","password":"password","otp":"`+recoveryCodes[0]+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 401, r.Code)
+		})
+
+	// the admin can't enforce two-factor authentication without using it
+	r.PUT("/v1/admin/settings/enforce_two_factor").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"enforce_two_factor":true}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+		})
+
+	setting := model.NewSetting(model.SettingEnforceTwoFactor, true)
+	storage.DB.Save(setting)
+
+	r.GET("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1004,"message":"two-factor authentication required"}}`, r.Body.String())
+		})
+
+	r.GET("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	// users without two-factor authentication can still enrol
+	r.POST("/v1/admin/totp").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	// without a configured secret two-factor authentication is neither enforced nor enrolled
+	model.Config.SecretGenerated = true
+
+	r.GET("/v1/admin/tickers").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.POST("/v1/admin/totp").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Contains(t, r.Body.String(), model.ErrorTOTPUnavailable)
+		})
+
+	model.Config.SecretGenerated = false
+	storage.DB.DeleteStruct(setting)
+
+	r.POST("/v1/admin/totp/disable").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"code":"000000"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+		})
+
+	r.POST("/v1/admin/totp/disable").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(`{"code":"`+recoveryCodes[1]+`"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Contains(t, r.Body.String(), `"totp_enabled":false`)
+		})
+
+	r.DELETE("/v1/admin/users/"+strconv.Itoa(user.ID)+"/totp").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.DELETE("/v1/admin/users/"+strconv.Itoa(user.ID)+"/totp").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/systemli/ticker/internal/media"
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+//PostUploadHandler stores the uploaded images and returns the created Uploads
+func PostUploadHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, Config.UploadMaxSize*MaxAttachments+(1<<20))
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, "Files: Is Required"))
+		return
+	}
+	if len(files) > MaxAttachments {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, fmt.Sprintf("Files: at most %d files are allowed", MaxAttachments)))
+		return
+	}
+
+	var images []*media.Image
+	for _, fh := range files {
+		if fh.Size > Config.UploadMaxSize {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, fmt.Sprintf("Files: %s is too large", fh.Filename)))
+			return
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+
+		img, err := media.Process(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, fmt.Sprintf("Files: %s: %s", fh.Filename, err.Error())))
+			return
+		}
+
+		images = append(images, img)
+	}
+
+	err = os.MkdirAll(Config.UploadPath, 0750)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	var uploads []Upload
+	for _, img := range images {
+		upload := NewUpload(ticker.ID, img.ContentType, media.Extensions[img.ContentType])
+		upload.Size = len(img.Data)
+		upload.Width = img.Width
+		upload.Height = img.Height
+
+		attachment := upload.Attachment()
+		err = ioutil.WriteFile(attachment.FilePath(), img.Data, 0640)
+		if err == nil {
+			err = ioutil.WriteFile(attachment.ThumbnailPath(), img.Thumbnail, 0640)
+		}
+		if err == nil {
+			err = DB.Save(upload)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+
+		uploads = append(uploads, *upload)
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("uploads", NewUploadsResponse(uploads)))
+}
+
+//GetMediaHandler serves an uploaded image or its thumbnail
+func GetMediaHandler(c *gin.Context) {
+	name := c.Param("id")
+	uuid := strings.TrimSuffix(strings.TrimSuffix(name, filepath.Ext(name)), "_thumb")
+
+	upload, err := FindUploadByUUID(uuid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorUploadNotFound))
+		return
+	}
+
+	var path string
+	attachment := upload.Attachment()
+	switch name {
+	case attachment.FileName():
+		path = attachment.FilePath()
+	case attachment.ThumbnailName():
+		path = attachment.ThumbnailPath()
+	default:
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorUploadNotFound))
+		return
+	}
+
+	// uploads are never changed, only deleted
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.File(path)
+}
@@ -0,0 +1,173 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestUploadHandlers(t *testing.T) {
+	r := setup()
+
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	model.Config.UploadPath = dir
+	model.Config.UploadURL = "https://api.demoticker.org"
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 800, 600)))
+
+	body, contentType := multipartFiles(t, map[string][]byte{"image.png": img.Bytes()})
+	r.POST("/v1/admin/tickers/1/uploads").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken, "Content-Type": contentType}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	body, contentType = multipartFiles(t, map[string][]byte{"document.pdf": []byte("%PDF-1.4")})
+	r.POST("/v1/admin/tickers/1/uploads").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken, "Content-Type": contentType}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Files: document.pdf: unsupported content type application/pdf"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	model.Config.UploadMaxSize = 100
+	body, contentType = multipartFiles(t, map[string][]byte{"image.png": img.Bytes()})
+	r.POST("/v1/admin/tickers/1/uploads").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken, "Content-Type": contentType}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Files: image.png is too large"}}`, strings.TrimSpace(r.Body.String()))
+		})
+	model.Config.UploadMaxSize = 10 << 20
+
+	var upload model.UploadResponse
+	r.POST("/v1/admin/tickers/1/uploads").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken, "Content-Type": contentType}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.UploadResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, len(jres.Data["uploads"]))
+
+			upload = jres.Data["uploads"][0]
+			assert.Equal(t, "image/png", upload.ContentType)
+			assert.Equal(t, 800, upload.Width)
+			assert.Equal(t, 600, upload.Height)
+			assert.Equal(t, fmt.Sprintf("https://api.demoticker.org/v1/media/%s.png", upload.UUID), upload.URL)
+			assert.Equal(t, fmt.Sprintf("https://api.demoticker.org/v1/media/%s_thumb.png", upload.UUID), upload.ThumbnailURL)
+		})
+
+	r.GET(fmt.Sprintf("/v1/media/%s.png", upload.UUID)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+			assert.Equal(t, "image/png", r.HeaderMap.Get("Content-Type"))
+		})
+
+	r.GET(fmt.Sprintf("/v1/media/%s_thumb.png", upload.UUID)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			thumbnail, err := png.Decode(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 400, thumbnail.Bounds().Dx())
+			assert.Equal(t, 300, thumbnail.Bounds().Dy())
+		})
+
+	r.GET(fmt.Sprintf("/v1/media/%s.jpg", upload.UUID)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message","attachments":[42]}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Attachments: upload 42 not found"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(fmt.Sprintf(`{"text":"message","attachments":[%d]}`, upload.ID)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.MessageResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, len(jres.Data["message"].Attachments))
+			assert.Equal(t, upload.URL, jres.Data["message"].Attachments[0].URL)
+			assert.Equal(t, upload.ThumbnailURL, jres.Data["message"].Attachments[0].ThumbnailURL)
+		})
+
+	r.PUT("/v1/admin/tickers/1/reset").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Empty(t, files)
+}
+
+func multipartFiles(t *testing.T, files map[string][]byte) (string, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := w.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	w.Close()
+
+	return body.String(), w.FormDataContentType()
+}
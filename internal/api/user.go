@@ -115,11 +115,13 @@ func PutUserHandler(c *gin.Context) {
 	}
 
 	var body struct {
-		Email        string `json:"email,omitempty" validate:"email"`
-		Password     string `json:"password,omitempty" validate:"min=10"`
-		Role         string `json:"role,omitempty"`
-		IsSuperAdmin bool   `json:"is_super_admin,omitempty"`
-		Tickers      []int  `json:"tickers,omitempty"`
+		Email        string         `json:"email,omitempty" validate:"email"`
+		Password     string         `json:"password,omitempty" validate:"min=10"`
+		Role         string         `json:"role,omitempty"`
+		IsSuperAdmin bool           `json:"is_super_admin,omitempty"`
+		Tickers      []int          `json:"tickers,omitempty"`
+		TickerRoles  map[int]string `json:"ticker_roles,omitempty"`
+		TelegramID   *int64         `json:"telegram_id,omitempty"`
 	}
 
 	err = c.Bind(&body)
@@ -128,6 +130,12 @@ func PutUserHandler(c *gin.Context) {
 		return
 	}
 
+	// the effective roles before the update, to notify the tickers whose users changed
+	previousRoles := make(map[int]string)
+	for _, tickerID := range user.Tickers {
+		previousRoles[tickerID] = user.TickerRole(tickerID)
+	}
+
 	if body.Email != "" {
 		user.Email = body.Email
 	}
@@ -135,8 +143,21 @@ func PutUserHandler(c *gin.Context) {
 		user.UpdatePassword(body.Password)
 	}
 	if body.Role != "" {
+		err = ValidateRole(body.Role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
 		user.Role = body.Role
 	}
+	for tickerID, role := range body.TickerRoles {
+		err = ValidateRole(role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+		user.SetTickerRole(Ticker{ID: tickerID}, role)
+	}
 
 	me, err := Me(c)
 	if err != nil {
@@ -151,6 +172,21 @@ func PutUserHandler(c *gin.Context) {
 	if body.Tickers != nil {
 		user.Tickers = body.Tickers
 	}
+	for tickerID := range user.TickerRoles {
+		if !contains(user.Tickers, tickerID) {
+			delete(user.TickerRoles, tickerID)
+		}
+	}
+	if body.TelegramID != nil {
+		if *body.TelegramID != 0 {
+			other, err := FindUserByTelegramID(*body.TelegramID)
+			if err == nil && other.ID != user.ID {
+				c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, "telegram id is already in use"))
+				return
+			}
+		}
+		user.TelegramID = *body.TelegramID
+	}
 
 	err = DB.Save(&user)
 	if err != nil {
@@ -158,6 +194,17 @@ func PutUserHandler(c *gin.Context) {
 		return
 	}
 
+	for _, tickerID := range user.Tickers {
+		if role, ok := previousRoles[tickerID]; !ok || role != user.TickerRole(tickerID) {
+			usersChanged(tickerID)
+		}
+	}
+	for tickerID := range previousRoles {
+		if !contains(user.Tickers, tickerID) {
+			usersChanged(tickerID)
+		}
+	}
+
 	c.JSON(http.StatusOK, NewJSONSuccessResponse("user", NewUserResponse(user)))
 }
 
@@ -192,12 +239,22 @@ func DeleteUserHandler(c *gin.Context) {
 		return
 	}
 
+	err = DeleteAPITokensByUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
 	err = DB.DeleteStruct(&user)
 	if err != nil {
 		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
 		return
 	}
 
+	for _, tickerID := range user.Tickers {
+		usersChanged(tickerID)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"data":   nil,
 		"status": ResponseSuccess,
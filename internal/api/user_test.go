@@ -5,6 +5,7 @@ import (
 	"github.com/appleboy/gofight"
 	"github.com/stretchr/testify/assert"
 	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/hub"
 	"github.com/systemli/ticker/internal/model"
 	"github.com/systemli/ticker/internal/storage"
 	"strings"
@@ -118,9 +119,11 @@ func TestPutUserHandler(t *testing.T) {
 	body := `{
 		"email": "new@systemli.org",
 		"password": "password13",
-		"role": "user",
+		"role": "contributor",
 		"is_super_admin": true,
-		"tickers": [1,2,3]
+		"tickers": [1,2,3],
+		"ticker_roles": {"1": "owner", "4": "viewer"},
+		"telegram_id": 100
 	}`
 
 	r.PUT("/v1/admin/users/2").
@@ -153,9 +156,11 @@ func TestPutUserHandler(t *testing.T) {
 			assert.Equal(t, 1, len(response.Data))
 			assert.Equal(t, 2, response.Data["user"].ID)
 			assert.Equal(t, "new@systemli.org", response.Data["user"].Email)
-			assert.Equal(t, "user", response.Data["user"].Role)
+			assert.Equal(t, model.RoleContributor, response.Data["user"].Role)
+			assert.Equal(t, map[int]string{1: model.RoleOwner}, response.Data["user"].TickerRoles)
 			assert.True(t, response.Data["user"].IsSuperAdmin)
 			assert.Equal(t, []int{1, 2, 3}, response.Data["user"].Tickers)
+			assert.Equal(t, int64(100), response.Data["user"].TelegramID)
 
 			var user model.User
 			err = storage.DB.One("ID", 2, &user)
@@ -166,6 +171,23 @@ func TestPutUserHandler(t *testing.T) {
 			assert.NotEmpty(t, user.EncryptedPassword)
 			assert.Equal(t, true, user.IsSuperAdmin)
 			assert.Equal(t, []int{1, 2, 3}, user.Tickers)
+			assert.Equal(t, int64(100), user.TelegramID)
+		})
+
+	r.PUT("/v1/admin/users/2").
+		SetBody(`{"role": "admin"}`).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Role: must be one of owner, editor, contributor, viewer"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.PUT("/v1/admin/users/1").
+		SetBody(`{"telegram_id": 100}`).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"telegram id is already in use"}}`, strings.TrimSpace(r.Body.String()))
 		})
 
 	body = `{
@@ -196,6 +218,44 @@ func TestPutUserHandler(t *testing.T) {
 		})
 }
 
+func TestPutUserHandlerTickerRoles(t *testing.T) {
+	r := setup()
+
+	r.PUT("/v1/admin/users/2").
+		SetBody(`{"tickers": [1]}`).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	events := hub.Admin.Subscribe(1)
+	defer events.Close()
+
+	r.PUT("/v1/admin/users/2").
+		SetBody(`{"ticker_roles": {"1": "viewer"}}`).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	select {
+	case event := <-events.Events():
+		assert.Equal(t, hub.TickerUsersUpdated, event.Type)
+	default:
+		t.Error("no users event")
+	}
+
+	// an unchanged role doesn't notify the ticker
+	r.PUT("/v1/admin/users/2").
+		SetBody(`{"ticker_roles": {"1": "viewer"}}`).
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	assert.Empty(t, events.Events())
+}
+
 func TestDeleteUserHandler(t *testing.T) {
 	r := setup()
 
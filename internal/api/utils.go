@@ -1,6 +1,7 @@
 package api
 
 import (
+	"net"
 	"net/url"
 	"strings"
 
@@ -10,17 +11,48 @@ import (
 	"github.com/pkg/errors"
 )
 
-//
+//GetDomain resolves the domain of the requested ticker. Routes with a ticker slug always use the slug,
+//otherwise the configured strategies are tried in order.
 func GetDomain(c *gin.Context) (string, error) {
+	if slug := c.Param("slug"); slug != "" {
+		return model.NormalizeDomain(slug), nil
+	}
+
+	strategies := []string{model.DomainResolutionOrigin}
+	if model.Config != nil && len(model.Config.DomainResolution) > 0 {
+		strategies = model.Config.DomainResolution
+	}
+
+	for _, strategy := range strategies {
+		var domain string
+		var err error
+
+		switch strategy {
+		case model.DomainResolutionOrigin:
+			domain, err = originDomain(c)
+		case model.DomainResolutionHost:
+			domain = model.NormalizeDomain(c.Request.Host)
+		case model.DomainResolutionForwardedHost:
+			domain = forwardedDomain(c)
+		}
+
+		if err != nil {
+			return "", err
+		}
+		if domain != "" {
+			return domain, nil
+		}
+	}
+
+	return "", errors.New("Origin header not found")
+}
+
+//originDomain returns the domain from the Origin header or the origin query parameter.
+func originDomain(c *gin.Context) (string, error) {
 	origin := c.Request.Header.Get("Origin")
 
 	if origin == "" {
-		origin = c.Request.URL.Query().Get("origin")
-		if origin == "" {
-			return "", errors.New("Origin header not found")
-		} else {
-			return origin, nil
-		}
+		return model.NormalizeDomain(c.Request.URL.Query().Get("origin")), nil
 	}
 
 	u, err := url.Parse(origin)
@@ -28,16 +60,25 @@ func GetDomain(c *gin.Context) (string, error) {
 		return "", err
 	}
 
-	domain := u.Host
-	if strings.HasPrefix(domain, "www.") {
-		domain = domain[4:]
+	return model.NormalizeDomain(u.Host), nil
+}
+
+//forwardedDomain returns the domain from the X-Forwarded-Host header when the request comes from a trusted proxy.
+func forwardedDomain(c *gin.Context) string {
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		host = c.Request.RemoteAddr
 	}
-	if strings.Contains(domain, ":") {
-		parts := strings.Split(domain, ":")
-		domain = parts[0]
+
+	ip := net.ParseIP(host)
+	if ip == nil || model.Config == nil || !model.Config.TrustedProxy(ip) {
+		return ""
 	}
 
-	return domain, nil
+	// the first entry is the host requested by the client
+	forwarded := strings.Split(c.Request.Header.Get("X-Forwarded-Host"), ",")[0]
+
+	return model.NormalizeDomain(forwarded)
 }
 
 func Me(c *gin.Context) (model.User, error) {
@@ -50,11 +91,79 @@ func Me(c *gin.Context) (model.User, error) {
 	return u.(model.User), nil
 }
 
+//CurrentTicker returns the ticker loaded by the TickerMiddleware.
+func CurrentTicker(c *gin.Context) (model.Ticker, error) {
+	var ticker model.Ticker
+	t, exists := c.Get(TickerKey)
+	if !exists {
+		return ticker, errors.New(model.ErrorTickerNotFound)
+	}
+
+	return t.(model.Ticker), nil
+}
+
+//CurrentMessage returns the message loaded by the MessageMiddleware.
+func CurrentMessage(c *gin.Context) (model.Message, error) {
+	var message model.Message
+	m, exists := c.Get(MessageKey)
+	if !exists {
+		return message, errors.New(model.ErrorMessageNotFound)
+	}
+
+	return m.(model.Message), nil
+}
+
+//CurrentAPIToken returns the API token the request was authenticated with.
+func CurrentAPIToken(c *gin.Context) (model.APIToken, bool) {
+	t, exists := c.Get(APITokenKey)
+	if !exists {
+		return model.APIToken{}, false
+	}
+
+	return t.(model.APIToken), true
+}
+
+//Allowed returns true when the user has the permission on the ticker and the API token of the request, if any, permits it.
+func Allowed(c *gin.Context, tickerID int, permission string) bool {
+	me, err := Me(c)
+	if err != nil || !me.Can(tickerID, permission) {
+		return false
+	}
+
+	if token, ok := CurrentAPIToken(c); ok {
+		return token.Allows(tickerID, permission)
+	}
+
+	return true
+}
+
+//Permissions returns the permissions of the request on the ticker.
+func Permissions(c *gin.Context, tickerID int) []string {
+	me, err := Me(c)
+	if err != nil {
+		return nil
+	}
+
+	var permissions []string
+	for _, permission := range me.Permissions(tickerID) {
+		if Allowed(c, tickerID, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions
+}
+
 func IsAdmin(c *gin.Context) bool {
 	u, err := Me(c)
 	if err != nil {
 		return false
 	}
 
+	// restricted api tokens never act as administrator
+	if token, ok := CurrentAPIToken(c); ok && token.Restricted() {
+		return false
+	}
+
 	return u.IsSuperAdmin
 }
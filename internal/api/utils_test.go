@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
 	"net/http"
 	"net/url"
 	"testing"
@@ -61,3 +62,61 @@ func TestGetDomainWWW(t *testing.T) {
 	assert.Equal(t, "demoticker.org", domain)
 	assert.Equal(t, nil, err)
 }
+
+func TestGetDomainHost(t *testing.T) {
+	model.Config = model.NewConfig()
+	model.Config.DomainResolution = []string{model.DomainResolutionOrigin, model.DomainResolutionHost}
+
+	req := http.Request{Host: "www.demoticker.org:8080", URL: &url.URL{}}
+	c := gin.Context{Request: &req}
+
+	domain, err := api.GetDomain(&c)
+	assert.Equal(t, "demoticker.org", domain)
+	assert.Equal(t, nil, err)
+
+	req.Header = http.Header{"Origin": []string{"http://prozessticker.org/"}}
+
+	domain, err = api.GetDomain(&c)
+	assert.Equal(t, "prozessticker.org", domain)
+	assert.Equal(t, nil, err)
+}
+
+func TestGetDomainForwardedHost(t *testing.T) {
+	model.Config = model.NewConfig()
+	model.Config.DomainResolution = []string{model.DomainResolutionForwardedHost}
+	model.Config.TrustedProxies = []string{"10.0.0.0/8", "::1"}
+
+	req := http.Request{
+		Header:     http.Header{"X-Forwarded-Host": []string{"demoticker.org, proxy.internal"}},
+		RemoteAddr: "10.1.2.3:41234",
+		URL:        &url.URL{},
+	}
+	c := gin.Context{Request: &req}
+
+	domain, err := api.GetDomain(&c)
+	assert.Equal(t, "demoticker.org", domain)
+	assert.Equal(t, nil, err)
+
+	req.RemoteAddr = "[::1]:41234"
+
+	domain, err = api.GetDomain(&c)
+	assert.Equal(t, "demoticker.org", domain)
+	assert.Equal(t, nil, err)
+
+	req.RemoteAddr = "192.168.1.1:41234"
+
+	domain, err = api.GetDomain(&c)
+	assert.Equal(t, "", domain)
+	assert.NotNil(t, err)
+}
+
+func TestGetDomainSlug(t *testing.T) {
+	req := http.Request{
+		Header: http.Header{"Origin": []string{"http://prozessticker.org/"}},
+	}
+	c := gin.Context{Request: &req, Params: gin.Params{{Key: "slug", Value: "demoticker.org"}}}
+
+	domain, err := api.GetDomain(&c)
+	assert.Equal(t, "demoticker.org", domain)
+	assert.Equal(t, nil, err)
+}
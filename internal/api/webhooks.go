@@ -0,0 +1,164 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sethvargo/go-password/password"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+const webhookDeliveriesLimit = 50
+
+//GetWebhooksHandler returns all Webhooks for the ticker
+func GetWebhooksHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	webhooks, err := FindWebhooksByTicker(ticker.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("webhooks", NewWebhooksResponse(webhooks)))
+}
+
+//PostWebhookHandler creates and returns a new Webhook
+func PostWebhookHandler(c *gin.Context) {
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return
+	}
+
+	var body struct {
+		URL    string   `json:"url" binding:"required"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events" binding:"required"`
+	}
+
+	err = c.Bind(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	err = validateWebhook(body.URL, body.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	webhook := NewWebhook()
+	webhook.Ticker = ticker.ID
+	webhook.URL = body.URL
+	webhook.Events = body.Events
+	webhook.Secret = body.Secret
+
+	if webhook.Secret == "" {
+		webhook.Secret, err = password.Generate(32, 10, 0, false, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+			return
+		}
+	}
+
+	err = DB.Save(webhook)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	response := NewWebhookResponse(*webhook)
+	response.Secret = webhook.Secret
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("webhook", response))
+}
+
+//DeleteWebhookHandler deletes a Webhook and its deliveries
+func DeleteWebhookHandler(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := DeleteWebhook(webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   nil,
+		"status": ResponseSuccess,
+		"error":  nil,
+	})
+}
+
+//GetWebhookDeliveriesHandler returns the latest deliveries for a Webhook
+func GetWebhookDeliveriesHandler(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := FindWebhookDeliveries(webhook.ID, webhookDeliveriesLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewJSONSuccessResponse("deliveries", NewWebhookDeliveriesResponse(deliveries)))
+}
+
+func findWebhook(c *gin.Context) (Webhook, bool) {
+	var webhook Webhook
+
+	ticker, err := CurrentTicker(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorTickerNotFound))
+		return webhook, false
+	}
+
+	webhookID, err := strconv.Atoi(c.Param("webhookID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewJSONErrorResponse(ErrorCodeDefault, err.Error()))
+		return webhook, false
+	}
+
+	err = DB.One("ID", webhookID, &webhook)
+	if err != nil || webhook.Ticker != ticker.ID {
+		c.JSON(http.StatusNotFound, NewJSONErrorResponse(ErrorCodeNotFound, ErrorWebhookNotFound))
+		return webhook, false
+	}
+
+	return webhook, true
+}
+
+func validateWebhook(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("URL: invalid url")
+	}
+
+	if len(events) == 0 {
+		return errors.New("Events: Is Required")
+	}
+
+	for _, event := range events {
+		if !ValidWebhookEvent(event) {
+			return errors.New("Events: unknown event " + event)
+		}
+	}
+
+	return nil
+}
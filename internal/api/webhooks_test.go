@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/appleboy/gofight"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/webhook"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	r := setup()
+
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, r.Header.Get(webhook.EventHeader))
+	}))
+	defer server.Close()
+
+	ticker := model.Ticker{
+		ID:     1,
+		Active: true,
+	}
+
+	storage.DB.Save(&ticker)
+
+	body := fmt.Sprintf(`{"url":"%s","events":["message.created","message.deleted"]}`, server.URL)
+
+	r.POST("/v1/admin/tickers/1/webhooks").
+		SetHeader(map[string]string{"Authorization": "Bearer " + UserToken}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 403, r.Code)
+		})
+
+	r.POST("/v1/admin/tickers/1/webhooks").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"url":"ftp://example.org","events":["message.created"]}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"URL: invalid url"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.POST("/v1/admin/tickers/1/webhooks").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(fmt.Sprintf(`{"url":"%s","events":["message.updated"]}`, server.URL)).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 400, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1000,"message":"Events: unknown event message.updated"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.POST("/v1/admin/tickers/1/webhooks").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(body).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string]model.WebhookResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, jres.Data["webhook"].ID)
+			assert.Equal(t, server.URL, jres.Data["webhook"].URL)
+			assert.Equal(t, 32, len(jres.Data["webhook"].Secret))
+			assert.Equal(t, []string{model.EventMessageCreated, model.EventMessageDeleted}, jres.Data["webhook"].Events)
+		})
+
+	r.GET("/v1/admin/tickers/1/webhooks").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.WebhookResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, len(jres.Data["webhooks"]))
+			assert.Empty(t, jres.Data["webhooks"][0].Secret)
+		})
+
+	r.POST("/v1/admin/tickers/1/messages").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		SetBody(`{"text":"message"}`).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	r.DELETE("/v1/admin/tickers/1/messages/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	webhook.Wait()
+
+	assert.ElementsMatch(t, []string{model.EventMessageCreated, model.EventMessageDeleted}, events)
+
+	r.GET("/v1/admin/tickers/1/webhooks/1/deliveries").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+
+			var jres struct {
+				Data map[string][]model.WebhookDeliveryResponse `json:"data"`
+			}
+
+			err := json.Unmarshal(r.Body.Bytes(), &jres)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, len(jres.Data["deliveries"]))
+			assert.Equal(t, 200, jres.Data["deliveries"][0].StatusCode)
+		})
+
+	r.DELETE("/v1/admin/tickers/2/webhooks/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 404, r.Code)
+			assert.Equal(t, `{"data":{},"status":"error","error":{"code":1001,"message":"ticker not found"}}`, strings.TrimSpace(r.Body.String()))
+		})
+
+	r.DELETE("/v1/admin/tickers/1/webhooks/1").
+		SetHeader(map[string]string{"Authorization": "Bearer " + AdminToken}).
+		Run(api.API(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, 200, r.Code)
+		})
+
+	webhooks, _ := storage.FindWebhooksByTicker(1)
+	assert.Empty(t, webhooks)
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	log "github.com/sirupsen/logrus"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/telegram"
+)
+
+const (
+	//PollTimeout is the long polling timeout in seconds for getUpdates.
+	PollTimeout = 30
+
+	replyUnauthorized    = "You are not allowed to publish messages. Ask an admin to connect your Telegram ID %d with your account."
+	replyNoTicker        = "You don't have access to any ticker."
+	replySelectTicker    = "You have access to more than one ticker. Choose one with /ticker <id>.\n\n%s"
+	replyTickerSelected  = "Messages will be published to %s."
+	replyTickerForbidden = "You don't have access to this ticker."
+	replyPublished       = "Message published to %s."
+	replySubmitted       = "Message submitted to %s. It will be published after another member approved it."
+	replyFailed          = "The message could not be published."
+)
+
+//PublishFunc stores and distributes a new message for the ticker.
+type PublishFunc func(ticker Ticker, message *Message) error
+
+//TelegramBot receives messages from authorized users via long polling and publishes them.
+type TelegramBot struct {
+	telegram.API
+	Publish PublishFunc
+
+	offset   int
+	selected map[int64]int
+}
+
+type update struct {
+	UpdateID int `json:"update_id"`
+	Message  *struct {
+		MessageID int    `json:"message_id"`
+		Text      string `json:"text"`
+		From      struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Chat struct {
+			ID   int64  `json:"id"`
+			Type string `json:"type"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+//NewTelegramBot returns a TelegramBot which publishes with the given function.
+func NewTelegramBot(token, endpoint string, publish PublishFunc) *TelegramBot {
+	return &TelegramBot{
+		API: telegram.API{
+			Token:    token,
+			Endpoint: endpoint,
+			Client:   &http.Client{Timeout: (PollTimeout + 10) * time.Second},
+		},
+		Publish:  publish,
+		selected: make(map[int64]int),
+	}
+}
+
+//Run polls for updates until the context is done.
+func (tb *TelegramBot) Run(ctx context.Context) {
+	log.Info("Starting Telegram bot")
+
+	backoff := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		err := tb.Poll(PollTimeout)
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+
+		log.WithError(err).Error("could not fetch telegram updates")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+//Poll fetches and handles the pending updates.
+func (tb *TelegramBot) Poll(timeout int) error {
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(tb.offset))
+	params.Set("timeout", strconv.Itoa(timeout))
+	params.Set("allowed_updates", `["message"]`)
+
+	var updates []update
+	err := tb.Call("getUpdates", params, &updates)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range updates {
+		tb.offset = u.UpdateID + 1
+
+		if u.Message == nil || u.Message.Chat.Type != "private" || u.Message.Text == "" {
+			continue
+		}
+
+		reply := tb.handle(u.Message.From.ID, u.Message.Text)
+
+		params := url.Values{}
+		params.Set("chat_id", strconv.FormatInt(u.Message.Chat.ID, 10))
+		params.Set("reply_to_message_id", strconv.Itoa(u.Message.MessageID))
+		params.Set("text", reply)
+		if err := tb.Call("sendMessage", params, nil); err != nil {
+			log.WithError(err).Error("could not reply to telegram message")
+		}
+	}
+
+	return nil
+}
+
+func (tb *TelegramBot) handle(from int64, text string) string {
+	user, err := FindUserByTelegramID(from)
+	if err != nil {
+		return fmt.Sprintf(replyUnauthorized, from)
+	}
+
+	tickers, err := tb.tickers(*user)
+	if err != nil {
+		log.WithError(err).Error("could not find tickers for telegram user")
+		return replyFailed
+	}
+	if len(tickers) == 0 {
+		return replyNoTicker
+	}
+
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)
+		if command[0] == "/ticker" && len(command) > 1 {
+			id, _ := strconv.Atoi(command[1])
+			for _, ticker := range tickers {
+				if ticker.ID == id {
+					tb.selected[from] = id
+					return fmt.Sprintf(replyTickerSelected, ticker.Title)
+				}
+			}
+			return replyTickerForbidden
+		}
+
+		if len(tickers) == 1 {
+			return fmt.Sprintf(replyTickerSelected, tickers[0].Title)
+		}
+		return fmt.Sprintf(replySelectTicker, tickerList(tickers))
+	}
+
+	var ticker *Ticker
+	if len(tickers) == 1 {
+		ticker = &tickers[0]
+	} else {
+		for i := range tickers {
+			if tickers[i].ID == tb.selected[from] {
+				ticker = &tickers[i]
+			}
+		}
+	}
+	if ticker == nil {
+		return fmt.Sprintf(replySelectTicker, tickerList(tickers))
+	}
+
+	message := NewMessage()
+	message.Text = text
+	message.Author = user.ID
+	message.Draft = !user.Can(ticker.ID, PermissionPublishMessages)
+
+	err = tb.Publish(*ticker, message)
+	if err != nil {
+		log.WithError(err).WithField("ticker", ticker.ID).Error("could not publish telegram message")
+		return replyFailed
+	}
+
+	if message.Draft {
+		return fmt.Sprintf(replySubmitted, ticker.Title)
+	}
+
+	return fmt.Sprintf(replyPublished, ticker.Title)
+}
+
+func (tb *TelegramBot) tickers(user User) ([]Ticker, error) {
+	var tickers []Ticker
+	var err error
+	if user.IsSuperAdmin {
+		err = DB.All(&tickers)
+	} else {
+		err = DB.Select(q.In("ID", user.Tickers)).Find(&tickers)
+	}
+	if err == storm.ErrNotFound {
+		return tickers, nil
+	}
+	if err != nil {
+		return tickers, err
+	}
+
+	// viewers can't send messages
+	var allowed []Ticker
+	for _, ticker := range tickers {
+		if user.Can(ticker.ID, PermissionCreateMessages) {
+			allowed = append(allowed, ticker)
+		}
+	}
+
+	return allowed, nil
+}
+
+func tickerList(tickers []Ticker) string {
+	var lines []string
+	for _, ticker := range tickers {
+		lines = append(lines, fmt.Sprintf("/ticker %d – %s", ticker.ID, ticker.Title))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,118 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/bot"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+type telegramAPI struct {
+	updates []string
+	replies []string
+}
+
+func (api *telegramAPI) server() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bottoken/getUpdates", func(w http.ResponseWriter, r *http.Request) {
+		var updates []json.RawMessage
+		for i, text := range api.updates {
+			updates = append(updates, json.RawMessage(fmt.Sprintf(
+				`{"update_id":%d,"message":{"message_id":%d,"text":%q,"from":{"id":100},"chat":{"id":100,"type":"private"}}}`,
+				i+1, i+1, text,
+			)))
+		}
+		api.updates = nil
+
+		res, _ := json.Marshal(map[string]interface{}{"ok": true, "result": updates})
+		w.Write(res)
+	})
+	mux.HandleFunc("/bottoken/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		api.replies = append(api.replies, r.FormValue("text"))
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestTelegramBot(t *testing.T) {
+	setup()
+
+	api := &telegramAPI{}
+	server := api.server()
+	defer server.Close()
+
+	var published []*model.Message
+	tb := bot.NewTelegramBot("token", server.URL, func(ticker model.Ticker, message *model.Message) error {
+		message.Ticker = ticker.ID
+		published = append(published, message)
+		return nil
+	})
+
+	api.updates = []string{"message"}
+	err := tb.Poll(0)
+	assert.Nil(t, err)
+	assert.Empty(t, published)
+	assert.Equal(t, []string{"You are not allowed to publish messages. Ask an admin to connect your Telegram ID 100 with your account."}, api.replies)
+
+	user, _ := model.NewUser("louis@systemli.org", "password")
+	user.TelegramID = 100
+	storage.DB.Save(user)
+
+	api.replies = nil
+	api.updates = []string{"message"}
+	err = tb.Poll(0)
+	assert.Nil(t, err)
+	assert.Empty(t, published)
+	assert.Equal(t, []string{"You don't have access to any ticker."}, api.replies)
+
+	t1 := &model.Ticker{ID: 1, Title: "First"}
+	t2 := &model.Ticker{ID: 2, Title: "Second"}
+	storage.DB.Save(t1)
+	storage.DB.Save(t2)
+
+	user.Tickers = []int{1}
+	storage.DB.Save(user)
+
+	api.replies = nil
+	api.updates = []string{"first message"}
+	err = tb.Poll(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(published))
+	assert.Equal(t, "first message", published[0].Text)
+	assert.Equal(t, 1, published[0].Ticker)
+	assert.Equal(t, []string{"Message published to First."}, api.replies)
+
+	user.Tickers = []int{1, 2}
+	storage.DB.Save(user)
+
+	api.replies = nil
+	api.updates = []string{"second message", "/ticker 3", "/ticker 2", "second message"}
+	err = tb.Poll(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(published))
+	assert.Equal(t, "second message", published[1].Text)
+	assert.Equal(t, 2, published[1].Ticker)
+	assert.Equal(t, []string{
+		"You have access to more than one ticker. Choose one with /ticker <id>.\n\n/ticker 1 – First\n/ticker 2 – Second",
+		"You don't have access to this ticker.",
+		"Messages will be published to Second.",
+		"Message published to Second.",
+	}, api.replies)
+}
+
+func setup() {
+	if storage.DB == nil {
+		storage.DB = storage.OpenDB("ticker_test.db")
+	}
+	storage.DB.Drop("Ticker")
+	storage.DB.Drop("Message")
+	storage.DB.Drop("User")
+}
@@ -1,84 +1,125 @@
 package bridge
 
 import (
-	"github.com/dghubble/go-twitter/twitter"
-	"github.com/dghubble/oauth1"
+	"sort"
+	"sync"
+
 	"github.com/systemli/ticker/internal/model"
-	"strconv"
 )
 
-var Twitter *TwitterBridge
-
-//
-type TwitterBridge struct {
-	ConsumerKey    string
-	ConsumerSecret string
+var registry = struct {
+	sync.RWMutex
+	bridges map[string]Bridge
+}{bridges: make(map[string]Bridge)}
+
+//Bridge publishes ticker messages to an external channel.
+type Bridge interface {
+	//Enabled returns true when the bridge is configured and activated for the ticker.
+	Enabled(ticker model.Ticker) bool
+	//Update publishes the message and stores the remote reference on it.
+	Update(ticker model.Ticker, message *model.Message) error
+	//Delete removes a previously published message.
+	Delete(ticker model.Ticker, message model.Message) error
+	//Verify checks the credentials stored for the ticker.
+	Verify(ticker model.Ticker) error
 }
 
-//
-func NewTwitterBridge(key, secret string) *TwitterBridge {
-	return &TwitterBridge{
-		ConsumerKey:    key,
-		ConsumerSecret: secret,
-	}
+//Editor is implemented by bridges which can change already published messages.
+type Editor interface {
+	//Edit replaces the text of a previously published message.
+	Edit(ticker model.Ticker, message model.Message) error
 }
 
-//
-func (tb *TwitterBridge) Initialized() bool {
-	return tb.ConsumerKey != "" && tb.ConsumerSecret != ""
+//Errors maps the bridge name to the error it returned.
+type Errors map[string]error
+
+//Register adds the bridge to the registry. An existing bridge with the same name is replaced.
+func Register(name string, bridge Bridge) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.bridges[name] = bridge
 }
 
-//
-func (tb *TwitterBridge) Update(ticker model.Ticker, message model.Message) (*twitter.Tweet, error) {
-	client := tb.client(ticker.Twitter.Token, ticker.Twitter.Secret)
+//Unregister removes the bridge from the registry.
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
 
-	tweet, _, err := client.Statuses.Update(message.PrepareTweet(&ticker), nil)
-	if err != nil {
-		return tweet, err
-	}
+	delete(registry.bridges, name)
+}
 
-	return tweet, nil
+//Get returns the registered bridge or nil.
+func Get(name string) Bridge {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.bridges[name]
 }
 
-func (tb *TwitterBridge) Delete(ticker model.Ticker, tweetID string) error {
-	client := tb.client(ticker.Twitter.Token, ticker.Twitter.Secret)
+//Names returns the names of all registered bridges in alphabetical order.
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
 
-	id, err := strconv.ParseInt(tweetID, 10, 64)
-	if err != nil {
-		return err
+	var names []string
+	for name := range registry.bridges {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	_, _, err = client.Statuses.Destroy(id, nil)
-
-	return err
+	return names
 }
 
-//User returns the user information.
-func (tb *TwitterBridge) User(ticker model.Ticker) (*twitter.User, error) {
-	token := oauth1.NewToken(ticker.Twitter.Token, ticker.Twitter.Secret)
-	httpClient := tb.config().Client(oauth1.NoContext, token)
-
-	// Twitter client
-	client := twitter.NewClient(httpClient)
-	user, _, err := client.Accounts.VerifyCredentials(&twitter.AccountVerifyParams{
-		IncludeEmail:    twitter.Bool(false),
-		IncludeEntities: twitter.Bool(false),
-		SkipStatus:      twitter.Bool(true),
-	})
-
-	if err != nil {
-		return user, err
+//Update publishes the message to all bridges enabled for the ticker.
+func Update(ticker model.Ticker, message *model.Message) Errors {
+	errs := Errors{}
+	for _, name := range Names() {
+		bridge := Get(name)
+		if bridge == nil || !bridge.Enabled(ticker) {
+			continue
+		}
+
+		if err := bridge.Update(ticker, message); err != nil {
+			errs[name] = err
+		}
 	}
 
-	return user, nil
+	return errs
 }
 
-func (tb *TwitterBridge) config() *oauth1.Config {
-	return oauth1.NewConfig(tb.ConsumerKey, tb.ConsumerSecret)
+//Delete removes the message from all registered bridges.
+//Bridges ignore messages they never published.
+func Delete(ticker model.Ticker, message model.Message) Errors {
+	errs := Errors{}
+	for _, name := range Names() {
+		bridge := Get(name)
+		if bridge == nil {
+			continue
+		}
+
+		if err := bridge.Delete(ticker, message); err != nil {
+			errs[name] = err
+		}
+	}
+
+	return errs
 }
 
-func (tb *TwitterBridge) client(accessToken, accessSecret string) *twitter.Client {
-	token := oauth1.NewToken(accessToken, accessSecret)
-	httpClient := tb.config().Client(oauth1.NoContext, token)
-	return twitter.NewClient(httpClient)
+//Edit changes the published message on all registered bridges which support editing.
+//Bridges ignore messages they never published.
+func Edit(ticker model.Ticker, message model.Message) Errors {
+	errs := Errors{}
+	for _, name := range Names() {
+		editor, ok := Get(name).(Editor)
+		if !ok {
+			continue
+		}
+
+		if err := editor.Edit(ticker, message); err != nil {
+			errs[name] = err
+		}
+	}
+
+	return errs
 }
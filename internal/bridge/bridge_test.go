@@ -0,0 +1,126 @@
+package bridge_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/model"
+)
+
+type fakeBridge struct {
+	enabled bool
+	err     error
+	updated []int
+	deleted []int
+}
+
+type fakeEditor struct {
+	fakeBridge
+	edited []int
+}
+
+func (fe *fakeEditor) Edit(ticker model.Ticker, message model.Message) error {
+	fe.edited = append(fe.edited, message.ID)
+	return fe.err
+}
+
+func (fb *fakeBridge) Enabled(ticker model.Ticker) bool {
+	return fb.enabled
+}
+
+func (fb *fakeBridge) Update(ticker model.Ticker, message *model.Message) error {
+	fb.updated = append(fb.updated, message.ID)
+	return fb.err
+}
+
+func (fb *fakeBridge) Delete(ticker model.Ticker, message model.Message) error {
+	fb.deleted = append(fb.deleted, message.ID)
+	return fb.err
+}
+
+func (fb *fakeBridge) Verify(ticker model.Ticker) error {
+	return fb.err
+}
+
+func TestRegister(t *testing.T) {
+	fb := &fakeBridge{}
+	bridge.Register("fake", fb)
+	defer bridge.Unregister("fake")
+
+	assert.Equal(t, fb, bridge.Get("fake"))
+	assert.Contains(t, bridge.Names(), "fake")
+
+	bridge.Unregister("fake")
+
+	assert.Nil(t, bridge.Get("fake"))
+	assert.NotContains(t, bridge.Names(), "fake")
+}
+
+func TestUpdate(t *testing.T) {
+	enabled := &fakeBridge{enabled: true}
+	disabled := &fakeBridge{enabled: false}
+	failing := &fakeBridge{enabled: true, err: errors.New("failed")}
+
+	bridge.Register("enabled", enabled)
+	bridge.Register("disabled", disabled)
+	bridge.Register("failing", failing)
+	defer bridge.Unregister("enabled")
+	defer bridge.Unregister("disabled")
+	defer bridge.Unregister("failing")
+
+	message := model.NewMessage()
+	message.ID = 1
+
+	errs := bridge.Update(*model.NewTicker(), message)
+
+	assert.Equal(t, []int{1}, enabled.updated)
+	assert.Empty(t, disabled.updated)
+	assert.Equal(t, []int{1}, failing.updated)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs["failing"], "failed")
+}
+
+func TestDelete(t *testing.T) {
+	enabled := &fakeBridge{enabled: true}
+	disabled := &fakeBridge{enabled: false}
+
+	bridge.Register("enabled", enabled)
+	bridge.Register("disabled", disabled)
+	defer bridge.Unregister("enabled")
+	defer bridge.Unregister("disabled")
+
+	message := model.NewMessage()
+	message.ID = 1
+
+	errs := bridge.Delete(*model.NewTicker(), *message)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1}, enabled.deleted)
+	assert.Equal(t, []int{1}, disabled.deleted)
+}
+
+func TestEdit(t *testing.T) {
+	editor := &fakeEditor{}
+	other := &fakeBridge{enabled: true}
+	failing := &fakeEditor{fakeBridge: fakeBridge{err: errors.New("failed")}}
+
+	bridge.Register("editor", editor)
+	bridge.Register("other", other)
+	bridge.Register("failing", failing)
+	defer bridge.Unregister("editor")
+	defer bridge.Unregister("other")
+	defer bridge.Unregister("failing")
+
+	message := model.NewMessage()
+	message.ID = 1
+
+	errs := bridge.Edit(*model.NewTicker(), *message)
+
+	assert.Equal(t, []int{1}, editor.edited)
+	assert.Equal(t, []int{1}, failing.edited)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs["failing"], "failed")
+}
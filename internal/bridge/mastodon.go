@@ -0,0 +1,152 @@
+package bridge
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/systemli/ticker/internal/model"
+)
+
+const MastodonBridgeName = "mastodon"
+
+//MastodonBridge sends messages as statuses to the Mastodon account connected with the ticker.
+type MastodonBridge struct {
+	Client *http.Client
+}
+
+type mastodonStatus struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+type mastodonMedia struct {
+	ID string `json:"id"`
+}
+
+//
+func NewMastodonBridge() *MastodonBridge {
+	return &MastodonBridge{
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+//Enabled returns true when the ticker has an active and connected Mastodon account.
+func (mb *MastodonBridge) Enabled(ticker model.Ticker) bool {
+	return ticker.Mastodon.Active && ticker.Mastodon.Connected()
+}
+
+//Update posts the message as status and stores the status on the message.
+//Attachments are uploaded first and attached to the status.
+func (mb *MastodonBridge) Update(ticker model.Ticker, message *model.Message) error {
+	form := url.Values{}
+	form.Set("status", message.PrepareText(&ticker))
+
+	for _, attachment := range message.Attachments {
+		body, contentType, err := multipartBody(nil, map[string]model.Attachment{"file": attachment})
+		if err != nil {
+			return err
+		}
+
+		var m mastodonMedia
+		err = mb.do(ticker, http.MethodPost, "/api/v1/media", body, contentType, &m)
+		if err != nil {
+			return err
+		}
+
+		form.Add("media_ids[]", m.ID)
+	}
+
+	var status mastodonStatus
+	err := mb.request(ticker, http.MethodPost, "/api/v1/statuses", form, &status)
+	if err != nil {
+		return err
+	}
+
+	message.Mastodon = model.MastodonStatus{ID: status.ID, URL: status.URL}
+
+	return nil
+}
+
+//Edit changes the text of the status for the message.
+func (mb *MastodonBridge) Edit(ticker model.Ticker, message model.Message) error {
+	if message.Mastodon.ID == "" {
+		return nil
+	}
+
+	form := url.Values{}
+	form.Set("status", message.PrepareText(&ticker))
+
+	return mb.request(ticker, http.MethodPut, "/api/v1/statuses/"+url.PathEscape(message.Mastodon.ID), form, nil)
+}
+
+//Delete removes the status for the message.
+func (mb *MastodonBridge) Delete(ticker model.Ticker, message model.Message) error {
+	if message.Mastodon.ID == "" {
+		return nil
+	}
+
+	return mb.request(ticker, http.MethodDelete, "/api/v1/statuses/"+url.PathEscape(message.Mastodon.ID), nil, nil)
+}
+
+//Verify checks the Mastodon credentials of the ticker.
+func (mb *MastodonBridge) Verify(ticker model.Ticker) error {
+	_, err := mb.User(ticker)
+
+	return err
+}
+
+//User returns the account information.
+func (mb *MastodonBridge) User(ticker model.Ticker) (*model.MastodonUser, error) {
+	var user model.MastodonUser
+	err := mb.request(ticker, http.MethodGet, "/api/v1/accounts/verify_credentials", nil, &user)
+	if err != nil {
+		return &user, err
+	}
+
+	return &user, nil
+}
+
+func (mb *MastodonBridge) request(ticker model.Ticker, method, path string, form url.Values, v interface{}) error {
+	if form == nil {
+		return mb.do(ticker, method, path, nil, "", v)
+	}
+
+	return mb.do(ticker, method, path, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", v)
+}
+
+func (mb *MastodonBridge) do(ticker model.Ticker, method, path string, body io.Reader, contentType string, v interface{}) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(ticker.Mastodon.Server, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+ticker.Mastodon.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := mb.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&e)
+
+		return errors.Errorf("mastodon: %s %s returned %d %s", method, path, res.StatusCode, e.Error)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,122 @@
+package bridge_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/model"
+)
+
+func mastodonServer(t *testing.T, media []string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/media", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		_, header, err := r.FormFile("file")
+		assert.Nil(t, err)
+		assert.Equal(t, "a1.jpg", header.Filename)
+
+		w.Write([]byte(`{"id":"7"}`))
+	})
+	mux.HandleFunc("/api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "22:08 message", r.FormValue("status"))
+		r.ParseForm()
+		assert.Equal(t, media, r.PostForm["media_ids[]"])
+
+		w.Write([]byte(`{"id":"101","url":"https://mastodon.example/@ticker/101"}`))
+	})
+	mux.HandleFunc("/api/v1/statuses/101", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v1/accounts/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"The access token is invalid"}`))
+			return
+		}
+
+		w.Write([]byte(`{"id":"1","username":"ticker","display_name":"Ticker","url":"https://mastodon.example/@ticker"}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestMastodonBridge(t *testing.T) {
+	server := mastodonServer(t, nil)
+	defer server.Close()
+
+	mb := bridge.NewMastodonBridge()
+
+	ticker := model.NewTicker()
+	assert.False(t, mb.Enabled(*ticker))
+
+	ticker.PrependTime = true
+	ticker.Mastodon = model.Mastodon{Active: true, Server: server.URL + "/", Token: "token"}
+	assert.True(t, mb.Enabled(*ticker))
+
+	message := model.NewMessage()
+	message.CreationDate = time.Date(2012, 11, 1, 22, 8, 41, 0, time.UTC)
+	message.Text = "message"
+
+	err := mb.Update(*ticker, message)
+	assert.Nil(t, err)
+	assert.Equal(t, "101", message.Mastodon.ID)
+	assert.Equal(t, "https://mastodon.example/@ticker/101", message.Mastodon.URL)
+
+	err = mb.Delete(*ticker, *message)
+	assert.Nil(t, err)
+
+	user, err := mb.User(*ticker)
+	assert.Nil(t, err)
+	assert.Equal(t, "ticker", user.Username)
+	assert.Equal(t, "Ticker", user.DisplayName)
+
+	ticker.Mastodon.Token = "invalid"
+	err = mb.Verify(*ticker)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "401 The access token is invalid")
+}
+
+func TestMastodonBridgeAttachments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	model.Config = model.NewConfig()
+	model.Config.UploadPath = dir
+
+	attachment := model.Attachment{UUID: "a1", Extension: "jpg", ContentType: "image/jpeg"}
+	ioutil.WriteFile(filepath.Join(dir, attachment.FileName()), []byte("jpg"), 0644)
+
+	server := mastodonServer(t, []string{"7"})
+	defer server.Close()
+
+	mb := bridge.NewMastodonBridge()
+
+	ticker := model.NewTicker()
+	ticker.PrependTime = true
+	ticker.Mastodon = model.Mastodon{Active: true, Server: server.URL, Token: "token"}
+
+	message := model.NewMessage()
+	message.CreationDate = time.Date(2012, 11, 1, 22, 8, 41, 0, time.UTC)
+	message.Text = "message"
+	message.Attachments = []model.Attachment{attachment}
+
+	err = mb.Update(*ticker, message)
+	assert.Nil(t, err)
+	assert.Equal(t, "101", message.Mastodon.ID)
+}
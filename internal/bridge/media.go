@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/systemli/ticker/internal/model"
+)
+
+//multipartBody builds a multipart form with the params and the files of the attachments, keyed by form field.
+func multipartBody(params url.Values, files map[string]model.Attachment) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	for key, values := range params {
+		for _, value := range values {
+			if err := w.WriteField(key, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	// sort the fields to keep the order of the attachments
+	var fields []string
+	for field := range files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		attachment := files[field]
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+attachment.FileName()+`"`)
+		h.Set("Content-Type", attachment.ContentType)
+
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+
+		f, err := os.Open(attachment.FilePath())
+		if err != nil {
+			return nil, "", err
+		}
+
+		_, err = io.Copy(part, f)
+		f.Close()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body, w.FormDataContentType(), nil
+}
@@ -0,0 +1,203 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+var (
+	//MaxAttempts is the number of tries before a delivery is marked as failed.
+	MaxAttempts = 10
+	//RetryDelay is the initial delay between two attempts. It doubles with every retry.
+	RetryDelay = time.Minute
+	//ClaimTimeout is the time a retry run may take before its deliveries are retried by another run.
+	ClaimTimeout = 5 * time.Minute
+
+	//outbox guards the bookkeeping of the deliveries, the bridges are contacted without holding it
+	outbox sync.Mutex
+)
+
+//Deliver sends the message to all bridges enabled for the ticker and records every delivery in the outbox.
+//Failed deliveries are retried by RunOutbox. The message has to be stored already.
+func Deliver(ticker model.Ticker, message *model.Message) error {
+	for _, name := range Names() {
+		bridge := Get(name)
+		if bridge == nil || !bridge.Enabled(ticker) {
+			continue
+		}
+
+		// the delivery is recorded before the bridge is contacted, so it's retried when the process stops meanwhile
+		delivery := model.NewBridgeDelivery(*message, name)
+		delivery.NextAttempt = time.Now().Add(ClaimTimeout)
+		saveDelivery(delivery)
+
+		attempt(bridge, ticker, message, delivery)
+		saveDelivery(delivery)
+	}
+
+	return saveReferences(message)
+}
+
+func saveDelivery(delivery *model.BridgeDelivery) {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	err := storage.DB.Save(delivery)
+	if err != nil {
+		log.WithError(err).WithField("bridge", delivery.Bridge).Error("could not save delivery")
+	}
+}
+
+//Retry schedules the unsent deliveries of the message for an immediate retry and processes them.
+func Retry(message model.Message) error {
+	outbox.Lock()
+	deliveries, err := storage.FindDeliveriesByMessages([]int{message.ID})
+	if err == nil {
+		for _, delivery := range deliveries {
+			if delivery.State == model.DeliveryStateSent {
+				continue
+			}
+
+			delivery.State = model.DeliveryStatePending
+			delivery.Attempts = 0
+			delivery.NextAttempt = time.Now()
+			if err = storage.DB.Save(&delivery); err != nil {
+				break
+			}
+		}
+	}
+	outbox.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return RetryPending()
+}
+
+//RetryPending processes all pending deliveries which are due.
+func RetryPending() error {
+	deliveries, err := claimDueDeliveries()
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var message model.Message
+		if err := storage.DB.One("ID", delivery.Message, &message); err != nil {
+			// the message was deleted in the meantime
+			storage.DB.DeleteStruct(&delivery)
+			continue
+		}
+
+		var ticker model.Ticker
+		if err := storage.DB.One("ID", message.Ticker, &ticker); err != nil {
+			storage.DB.DeleteStruct(&delivery)
+			continue
+		}
+
+		bridge := Get(delivery.Bridge)
+		if bridge == nil || !bridge.Enabled(ticker) {
+			delivery.State = model.DeliveryStateFailed
+			delivery.LastError = "bridge is not enabled"
+		} else {
+			attempt(bridge, ticker, &message, &delivery)
+			if err := saveReferences(&message); err != nil {
+				log.WithError(err).WithField("message", message.ID).Error("could not save message")
+			}
+		}
+
+		saveDelivery(&delivery)
+	}
+
+	return nil
+}
+
+//claimDueDeliveries returns the due deliveries and postpones them, so concurrent runs don't send them twice
+//while the bridges are contacted. Deliveries of an interrupted run are due again after ClaimTimeout.
+func claimDueDeliveries() ([]model.BridgeDelivery, error) {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	deliveries, err := storage.FindDueDeliveries()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deliveries {
+		deliveries[i].NextAttempt = time.Now().Add(ClaimTimeout)
+		if err := storage.DB.Save(&deliveries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return deliveries, nil
+}
+
+//saveReferences stores the references to the published posts without overwriting other changes of the message
+//made while the bridges were contacted.
+func saveReferences(message *model.Message) error {
+	tx, err := storage.DB.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ref := &model.Message{ID: message.ID}
+	fields := map[string]interface{}{
+		"Tweet":    message.Tweet,
+		"Mastodon": message.Mastodon,
+		"Telegram": message.Telegram,
+	}
+	for field, value := range fields {
+		if err := tx.UpdateField(ref, field, value); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+//RunOutbox retries the pending deliveries in the given interval until the context is done.
+func RunOutbox(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := RetryPending(); err != nil {
+				log.WithError(err).Error("could not retry pending deliveries")
+			}
+		}
+	}
+}
+
+func attempt(bridge Bridge, ticker model.Ticker, message *model.Message, delivery *model.BridgeDelivery) {
+	delivery.Attempts++
+
+	err := bridge.Update(ticker, message)
+	if err == nil {
+		delivery.State = model.DeliveryStateSent
+		delivery.LastError = ""
+		return
+	}
+
+	log.WithError(err).WithField("bridge", delivery.Bridge).WithField("attempt", delivery.Attempts).Error("could not send message")
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= MaxAttempts {
+		delivery.State = model.DeliveryStateFailed
+		return
+	}
+
+	delivery.State = model.DeliveryStatePending
+	delivery.NextAttempt = time.Now().Add(RetryDelay * time.Duration(1<<uint(delivery.Attempts-1)))
+}
@@ -0,0 +1,200 @@
+package bridge_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestDeliver(t *testing.T) {
+	setup()
+
+	ok := &fakeBridge{enabled: true}
+	failing := &fakeBridge{enabled: true, err: errors.New("failed")}
+	bridge.Register("ok", ok)
+	bridge.Register("failing", failing)
+	defer bridge.Unregister("ok")
+	defer bridge.Unregister("failing")
+
+	ticker := model.NewTicker()
+	storage.DB.Save(ticker)
+
+	message := model.NewMessage()
+	message.Ticker = ticker.ID
+	storage.DB.Save(message)
+
+	err := bridge.Deliver(*ticker, message)
+	assert.Nil(t, err)
+
+	deliveries, err := storage.FindDeliveriesByMessages([]int{message.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deliveries))
+	assert.Equal(t, "failing", deliveries[0].Bridge)
+	assert.Equal(t, model.DeliveryStatePending, deliveries[0].State)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, "failed", deliveries[0].LastError)
+	assert.Equal(t, "ok", deliveries[1].Bridge)
+	assert.Equal(t, model.DeliveryStateSent, deliveries[1].State)
+
+	// the delivery is not due yet
+	err = bridge.RetryPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(failing.updated))
+
+	deliveries[0].NextAttempt = time.Now().Add(-time.Second)
+	storage.DB.Save(&deliveries[0])
+	failing.err = nil
+
+	err = bridge.RetryPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(failing.updated))
+	assert.Equal(t, 1, len(ok.updated))
+
+	deliveries, _ = storage.FindDeliveriesByMessages([]int{message.ID})
+	assert.Equal(t, model.DeliveryStateSent, deliveries[0].State)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Empty(t, deliveries[0].LastError)
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	setup()
+	bridge.RetryDelay = 0
+
+	failing := &fakeBridge{enabled: true, err: errors.New("failed")}
+	bridge.Register("failing", failing)
+	defer bridge.Unregister("failing")
+
+	ticker := model.NewTicker()
+	storage.DB.Save(ticker)
+
+	message := model.NewMessage()
+	message.Ticker = ticker.ID
+	storage.DB.Save(message)
+
+	bridge.Deliver(*ticker, message)
+	for i := 0; i < bridge.MaxAttempts; i++ {
+		bridge.RetryPending()
+	}
+
+	assert.Equal(t, bridge.MaxAttempts, len(failing.updated))
+
+	deliveries, _ := storage.FindDeliveriesByMessages([]int{message.ID})
+	assert.Equal(t, model.DeliveryStateFailed, deliveries[0].State)
+
+	failing.err = nil
+
+	err := bridge.Retry(*message)
+	assert.Nil(t, err)
+
+	deliveries, _ = storage.FindDeliveriesByMessages([]int{message.ID})
+	assert.Equal(t, model.DeliveryStateSent, deliveries[0].State)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+}
+
+func TestRetryPendingDeletedMessage(t *testing.T) {
+	setup()
+
+	delivery := model.NewBridgeDelivery(model.Message{ID: 42}, "failing")
+	storage.DB.Save(delivery)
+
+	err := bridge.RetryPending()
+	assert.Nil(t, err)
+
+	deliveries, _ := storage.FindDeliveriesByMessages([]int{42})
+	assert.Empty(t, deliveries)
+}
+
+//editingBridge changes the stored message while it is published, like an editor in the meantime.
+type editingBridge struct {
+	fakeBridge
+}
+
+func (eb *editingBridge) Update(ticker model.Ticker, message *model.Message) error {
+	storage.DB.UpdateField(&model.Message{ID: message.ID}, "Text", "edited")
+	message.Mastodon = model.MastodonStatus{ID: "1"}
+
+	return eb.fakeBridge.Update(ticker, message)
+}
+
+func TestRetryPendingKeepsChanges(t *testing.T) {
+	setup()
+
+	editing := &editingBridge{fakeBridge{enabled: true}}
+	bridge.Register("editing", editing)
+	defer bridge.Unregister("editing")
+
+	ticker := model.NewTicker()
+	storage.DB.Save(ticker)
+
+	message := model.NewMessage()
+	message.Ticker = ticker.ID
+	message.Text = "original"
+	storage.DB.Save(message)
+
+	storage.DB.Save(model.NewBridgeDelivery(*message, "editing"))
+
+	err := bridge.RetryPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(editing.updated))
+
+	var stored model.Message
+	storage.DB.One("ID", message.ID, &stored)
+	assert.Equal(t, "edited", stored.Text)
+	assert.Equal(t, "1", stored.Mastodon.ID)
+}
+
+//recordingBridge looks up the outbox while the message is published.
+type recordingBridge struct {
+	fakeBridge
+	deliveries []model.BridgeDelivery
+}
+
+func (rb *recordingBridge) Update(ticker model.Ticker, message *model.Message) error {
+	rb.deliveries, _ = storage.FindDeliveriesByMessages([]int{message.ID})
+
+	return rb.fakeBridge.Update(ticker, message)
+}
+
+func TestDeliverRecordsBeforeSending(t *testing.T) {
+	setup()
+
+	recording := &recordingBridge{fakeBridge: fakeBridge{enabled: true}}
+	bridge.Register("recording", recording)
+	defer bridge.Unregister("recording")
+
+	ticker := model.NewTicker()
+	storage.DB.Save(ticker)
+
+	message := model.NewMessage()
+	message.Ticker = ticker.ID
+	storage.DB.Save(message)
+
+	err := bridge.Deliver(*ticker, message)
+	assert.Nil(t, err)
+
+	// the delivery is pending while the bridge is contacted and not retried by another run meanwhile
+	assert.Equal(t, 1, len(recording.deliveries))
+	assert.Equal(t, model.DeliveryStatePending, recording.deliveries[0].State)
+	assert.True(t, recording.deliveries[0].NextAttempt.After(time.Now()))
+
+	deliveries, _ := storage.FindDeliveriesByMessages([]int{message.ID})
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, model.DeliveryStateSent, deliveries[0].State)
+}
+
+func setup() {
+	bridge.RetryDelay = time.Minute
+
+	if storage.DB == nil {
+		storage.DB = storage.OpenDB("ticker_test.db")
+	}
+	storage.DB.Drop("Ticker")
+	storage.DB.Drop("Message")
+	storage.DB.Drop("BridgeDelivery")
+}
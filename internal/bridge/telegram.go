@@ -0,0 +1,225 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/telegram"
+)
+
+const TelegramBridgeName = "telegram"
+
+//TelegramCaptionLength is the maximum length of a caption for photos.
+const TelegramCaptionLength = 1024
+
+//TelegramBridge sends messages via the Telegram Bot API to the channel configured for the ticker.
+type TelegramBridge struct {
+	telegram.API
+}
+
+//TelegramChat represents the chat information returned by the Bot API.
+type TelegramChat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+type telegramMessage struct {
+	MessageID int          `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+}
+
+//
+func NewTelegramBridge(token, endpoint string) *TelegramBridge {
+	return &TelegramBridge{
+		API: telegram.API{
+			Token:    token,
+			Endpoint: endpoint,
+			Client:   &http.Client{Timeout: 10 * time.Second},
+		},
+	}
+}
+
+//Enabled returns true when the ticker has an active Telegram chat.
+func (tb *TelegramBridge) Enabled(ticker model.Ticker) bool {
+	return tb.Token != "" && ticker.Telegram.Active && ticker.Telegram.Connected()
+}
+
+//Update sends the message to the chat and stores the message id on the message.
+//Attachments are sent as photos with the text as caption. When the text is too long
+//for a caption, it is sent as separate message before the photos.
+func (tb *TelegramBridge) Update(ticker model.Ticker, message *model.Message) error {
+	text := message.PrepareText(&ticker)
+	caption := ""
+	if len(message.Attachments) > 0 && utf8.RuneCountInString(text) <= TelegramCaptionLength {
+		caption = text
+	}
+
+	// the text might have been sent in a previous attempt
+	if caption == "" && message.Telegram.MessageID == 0 {
+		params := url.Values{}
+		params.Set("chat_id", ticker.Telegram.ChatID)
+		params.Set("text", text)
+
+		var tm telegramMessage
+		err := tb.Call("sendMessage", params, &tm)
+		if err != nil {
+			return err
+		}
+
+		message.Telegram = model.TelegramMessage{ChatID: ticker.Telegram.ChatID, MessageID: tm.MessageID}
+	}
+
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+
+	ids, err := tb.sendMedia(ticker.Telegram.ChatID, caption, message.Attachments)
+	if err != nil {
+		return err
+	}
+
+	if caption != "" {
+		message.Telegram = model.TelegramMessage{ChatID: ticker.Telegram.ChatID, MessageID: ids[0], MediaIDs: ids[1:]}
+	} else {
+		message.Telegram.MediaIDs = ids
+	}
+
+	return nil
+}
+
+//Edit changes the text of the message in the chat. Messages sent as photo get a new caption.
+func (tb *TelegramBridge) Edit(ticker model.Ticker, message model.Message) error {
+	if message.Telegram.MessageID == 0 {
+		return nil
+	}
+
+	text := message.PrepareText(&ticker)
+
+	params := url.Values{}
+	params.Set("chat_id", message.Telegram.ChatID)
+	params.Set("message_id", strconv.Itoa(message.Telegram.MessageID))
+
+	// the text was sent as caption of the first photo
+	if len(message.Attachments) > 0 && len(message.Telegram.MediaIDs) == len(message.Attachments)-1 {
+		if utf8.RuneCountInString(text) > TelegramCaptionLength {
+			return errors.New("telegram: text is too long for a caption")
+		}
+
+		params.Set("caption", text)
+		return tb.Call("editMessageCaption", params, nil)
+	}
+
+	params.Set("text", text)
+	return tb.Call("editMessageText", params, nil)
+}
+
+//Delete removes the message from the chat.
+func (tb *TelegramBridge) Delete(ticker model.Ticker, message model.Message) error {
+	if message.Telegram.MessageID == 0 {
+		return nil
+	}
+
+	ids := append([]int{message.Telegram.MessageID}, message.Telegram.MediaIDs...)
+	for _, id := range ids {
+		params := url.Values{}
+		params.Set("chat_id", message.Telegram.ChatID)
+		params.Set("message_id", strconv.Itoa(id))
+
+		err := tb.Call("deleteMessage", params, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Verify checks that the bot can access the chat of the ticker.
+func (tb *TelegramBridge) Verify(ticker model.Ticker) error {
+	_, err := tb.Chat(ticker)
+
+	return err
+}
+
+//Chat returns the chat information.
+func (tb *TelegramBridge) Chat(ticker model.Ticker) (*TelegramChat, error) {
+	params := url.Values{}
+	params.Set("chat_id", ticker.Telegram.ChatID)
+
+	var chat TelegramChat
+	err := tb.Call("getChat", params, &chat)
+	if err != nil {
+		return &chat, err
+	}
+
+	return &chat, nil
+}
+
+//sendMedia sends the attachments as photo or album and returns the ids of the sent messages.
+func (tb *TelegramBridge) sendMedia(chatID, caption string, attachments []model.Attachment) ([]int, error) {
+	params := url.Values{}
+	params.Set("chat_id", chatID)
+
+	if len(attachments) == 1 {
+		params.Set("caption", caption)
+
+		var tm telegramMessage
+		err := tb.upload("sendPhoto", params, map[string]model.Attachment{"photo": attachments[0]}, &tm)
+		if err != nil {
+			return nil, err
+		}
+
+		return []int{tm.MessageID}, nil
+	}
+
+	type inputMedia struct {
+		Type    string `json:"type"`
+		Media   string `json:"media"`
+		Caption string `json:"caption,omitempty"`
+	}
+
+	var media []inputMedia
+	files := make(map[string]model.Attachment)
+	for i, attachment := range attachments {
+		name := "file" + strconv.Itoa(i)
+		files[name] = attachment
+		media = append(media, inputMedia{Type: "photo", Media: "attach://" + name})
+	}
+	media[0].Caption = caption
+
+	encoded, err := json.Marshal(media)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("media", string(encoded))
+
+	var tms []telegramMessage
+	err = tb.upload("sendMediaGroup", params, files, &tms)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, tm := range tms {
+		ids = append(ids, tm.MessageID)
+	}
+
+	return ids, nil
+}
+
+func (tb *TelegramBridge) upload(method string, params url.Values, files map[string]model.Attachment, v interface{}) error {
+	body, contentType, err := multipartBody(params, files)
+	if err != nil {
+		return errors.Wrap(err, "telegram: "+method)
+	}
+
+	return tb.Post(method, body, contentType, v)
+}
@@ -0,0 +1,152 @@
+package bridge_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/bridge"
+	"github.com/systemli/ticker/internal/model"
+)
+
+func telegramServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bottoken/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "-1001", r.FormValue("chat_id"))
+		assert.Equal(t, "message", r.FormValue("text"))
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":42,"chat":{"id":-1001,"type":"channel","title":"Ticker"}}}`))
+	})
+	mux.HandleFunc("/bottoken/deleteMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "-1001", r.FormValue("chat_id"))
+		assert.Equal(t, "42", r.FormValue("message_id"))
+
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	mux.HandleFunc("/bottoken/getChat", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("chat_id") != "-1001" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"id":-1001,"type":"channel","title":"Ticker"}}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestTelegramBridge(t *testing.T) {
+	server := telegramServer(t)
+	defer server.Close()
+
+	tb := bridge.NewTelegramBridge("token", server.URL)
+
+	ticker := model.NewTicker()
+	assert.False(t, tb.Enabled(*ticker))
+
+	ticker.Telegram = model.Telegram{Active: true, ChatID: "-1001"}
+	assert.True(t, tb.Enabled(*ticker))
+
+	message := model.NewMessage()
+	message.Text = "message"
+
+	err := tb.Update(*ticker, message)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, message.Telegram.MessageID)
+	assert.Equal(t, "-1001", message.Telegram.ChatID)
+
+	err = tb.Delete(*ticker, *message)
+	assert.Nil(t, err)
+
+	chat, err := tb.Chat(*ticker)
+	assert.Nil(t, err)
+	assert.Equal(t, "Ticker", chat.Title)
+
+	ticker.Telegram.ChatID = "-1002"
+	err = tb.Verify(*ticker)
+	assert.NotNil(t, err)
+	assert.Equal(t, "telegram: getChat returned 400 Bad Request: chat not found", err.Error())
+}
+
+func TestTelegramBridgeAttachments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	model.Config = model.NewConfig()
+	model.Config.UploadPath = dir
+
+	a1 := model.Attachment{UUID: "a1", Extension: "jpg", ContentType: "image/jpeg"}
+	a2 := model.Attachment{UUID: "a2", Extension: "png", ContentType: "image/png"}
+	ioutil.WriteFile(filepath.Join(dir, a1.FileName()), []byte("jpg"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, a2.FileName()), []byte("png"), 0644)
+
+	var methods []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bottoken/sendMessage", func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, "sendMessage")
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	mux.HandleFunc("/bottoken/sendPhoto", func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, "sendPhoto")
+		assert.Equal(t, "caption", r.FormValue("caption"))
+
+		_, header, err := r.FormFile("photo")
+		assert.Nil(t, err)
+		assert.Equal(t, "a1.jpg", header.Filename)
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":2}}`))
+	})
+	mux.HandleFunc("/bottoken/sendMediaGroup", func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, "sendMediaGroup")
+
+		var media []map[string]string
+		json.Unmarshal([]byte(r.FormValue("media")), &media)
+		assert.Equal(t, 2, len(media))
+		assert.Equal(t, "attach://file0", media[0]["media"])
+		assert.Empty(t, media[0]["caption"])
+
+		_, _, err := r.FormFile("file1")
+		assert.Nil(t, err)
+
+		w.Write([]byte(`{"ok":true,"result":[{"message_id":3},{"message_id":4}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tb := bridge.NewTelegramBridge("token", server.URL)
+
+	ticker := model.NewTicker()
+	ticker.Telegram = model.Telegram{Active: true, ChatID: "-1001"}
+
+	message := model.NewMessage()
+	message.Text = "caption"
+	message.Attachments = []model.Attachment{a1}
+
+	err = tb.Update(*ticker, message)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sendPhoto"}, methods)
+	assert.Equal(t, 2, message.Telegram.MessageID)
+	assert.Empty(t, message.Telegram.MediaIDs)
+
+	methods = nil
+	message = model.NewMessage()
+	message.Text = strings.Repeat("a", bridge.TelegramCaptionLength+1)
+	message.Attachments = []model.Attachment{a1, a2}
+
+	err = tb.Update(*ticker, message)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sendMessage", "sendMediaGroup"}, methods)
+	assert.Equal(t, 1, message.Telegram.MessageID)
+	assert.Equal(t, []int{3, 4}, message.Telegram.MediaIDs)
+}
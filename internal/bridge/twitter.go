@@ -0,0 +1,195 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/pkg/errors"
+	"github.com/systemli/ticker/internal/model"
+)
+
+const TwitterBridgeName = "twitter"
+
+//TwitterUploadURL is the endpoint for media uploads which is not covered by the twitter client.
+var TwitterUploadURL = "https://upload.twitter.com/1.1/media/upload.json"
+
+//TwitterBridge sends messages to the Twitter account connected with the ticker.
+type TwitterBridge struct {
+	ConsumerKey    string
+	ConsumerSecret string
+}
+
+//
+func NewTwitterBridge(key, secret string) *TwitterBridge {
+	return &TwitterBridge{
+		ConsumerKey:    key,
+		ConsumerSecret: secret,
+	}
+}
+
+//
+func (tb *TwitterBridge) Initialized() bool {
+	return tb.ConsumerKey != "" && tb.ConsumerSecret != ""
+}
+
+//Enabled returns true when the ticker has an active and connected Twitter account.
+func (tb *TwitterBridge) Enabled(ticker model.Ticker) bool {
+	return tb.Initialized() && ticker.Twitter.Active && ticker.Twitter.Connected()
+}
+
+//Update sends the message as tweet and stores the tweet on the message.
+//Long messages are sent as a thread of replies. When a previous attempt failed in the middle
+//of a thread, the remaining tweets are appended to the already published ones.
+//Attachments are added to the first tweet.
+func (tb *TwitterBridge) Update(ticker model.Ticker, message *model.Message) error {
+	httpClient := tb.httpClient(ticker.Twitter.Token, ticker.Twitter.Secret)
+	client := twitter.NewClient(httpClient)
+	tweets := message.PrepareTweet(&ticker)
+
+	var previous string
+	if message.Tweet.ID != "" {
+		previous = message.Tweet.ID
+		if len(message.Tweet.Replies) > 0 {
+			previous = message.Tweet.Replies[len(message.Tweet.Replies)-1]
+		}
+
+		published := len(message.Tweet.Replies) + 1
+		if published > len(tweets) {
+			published = len(tweets)
+		}
+		tweets = tweets[published:]
+	}
+
+	for _, text := range tweets {
+		params := &twitter.StatusUpdateParams{}
+		if previous != "" {
+			id, err := strconv.ParseInt(previous, 10, 64)
+			if err != nil {
+				return err
+			}
+			params.InReplyToStatusID = id
+		} else {
+			for _, attachment := range message.Attachments {
+				id, err := tb.uploadMedia(httpClient, attachment)
+				if err != nil {
+					return err
+				}
+				params.MediaIds = append(params.MediaIds, id)
+			}
+		}
+
+		tweet, _, err := client.Statuses.Update(text, params)
+		if err != nil {
+			return err
+		}
+
+		if message.Tweet.ID == "" {
+			message.Tweet = model.Tweet{ID: tweet.IDStr, UserName: tweet.User.ScreenName}
+		} else {
+			message.Tweet.Replies = append(message.Tweet.Replies, tweet.IDStr)
+		}
+		previous = tweet.IDStr
+	}
+
+	return nil
+}
+
+//Delete removes the tweet for the message including all replies of the thread.
+func (tb *TwitterBridge) Delete(ticker model.Ticker, message model.Message) error {
+	if message.Tweet.ID == "" {
+		return nil
+	}
+
+	client := tb.client(ticker.Twitter.Token, ticker.Twitter.Secret)
+
+	// all tweets are tried, so a retry continues with the remaining tweets of a partially deleted thread
+	var failed []string
+	ids := append([]string{message.Tweet.ID}, message.Tweet.Replies...)
+	for i := len(ids) - 1; i >= 0; i-- {
+		id, err := strconv.ParseInt(ids[i], 10, 64)
+		if err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+
+		_, resp, err := client.Statuses.Destroy(id, nil)
+		// tweets deleted by a previous attempt are gone already
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("could not delete tweets: %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+//Verify checks the Twitter credentials of the ticker.
+func (tb *TwitterBridge) Verify(ticker model.Ticker) error {
+	_, err := tb.User(ticker)
+
+	return err
+}
+
+//User returns the user information.
+func (tb *TwitterBridge) User(ticker model.Ticker) (*twitter.User, error) {
+	client := tb.client(ticker.Twitter.Token, ticker.Twitter.Secret)
+	user, _, err := client.Accounts.VerifyCredentials(&twitter.AccountVerifyParams{
+		IncludeEmail:    twitter.Bool(false),
+		IncludeEntities: twitter.Bool(false),
+		SkipStatus:      twitter.Bool(true),
+	})
+
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (tb *TwitterBridge) config() *oauth1.Config {
+	return oauth1.NewConfig(tb.ConsumerKey, tb.ConsumerSecret)
+}
+
+func (tb *TwitterBridge) client(accessToken, accessSecret string) *twitter.Client {
+	return twitter.NewClient(tb.httpClient(accessToken, accessSecret))
+}
+
+func (tb *TwitterBridge) httpClient(accessToken, accessSecret string) *http.Client {
+	token := oauth1.NewToken(accessToken, accessSecret)
+	client := tb.config().Client(oauth1.NoContext, token)
+	client.Timeout = 10 * time.Second
+
+	return client
+}
+
+func (tb *TwitterBridge) uploadMedia(client *http.Client, attachment model.Attachment) (int64, error) {
+	body, contentType, err := multipartBody(nil, map[string]model.Attachment{"media": attachment})
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := client.Post(TwitterUploadURL, contentType, body)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return 0, errors.Errorf("twitter: media upload returned %d", res.StatusCode)
+	}
+
+	var media struct {
+		MediaID int64 `json:"media_id"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&media)
+
+	return media.MediaID, err
+}
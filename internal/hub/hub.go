@@ -0,0 +1,117 @@
+package hub
+
+import (
+	"sync"
+)
+
+const (
+	MessageCreated     = `message.created`
+	MessageUpdated     = `message.updated`
+	MessageDeleted     = `message.deleted`
+	TickerUpdated      = `ticker.updated`
+	TickerReset        = `ticker.reset`
+	TickerDeleted      = `ticker.deleted`
+	TickerUsersUpdated = `ticker.users.updated`
+)
+
+//BufferSize is the number of events buffered for a subscriber. Subscribers which fall behind are closed.
+var BufferSize = 32
+
+var (
+	//Timeline carries the changes of public messages. The data of the events is a model.Message.
+	Timeline = New()
+	//Admin carries all changes of a ticker for the connected editors. The data of the events is the API response.
+	Admin = New()
+)
+
+//Event is a change of a ticker.
+type Event struct {
+	Type   string      `json:"event"`
+	Ticker int         `json:"ticker"`
+	Data   interface{} `json:"data"`
+}
+
+//Hub distributes events to the subscribers of a ticker.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int]map[*Subscription]struct{}
+}
+
+//Subscription receives the events of a single ticker.
+type Subscription struct {
+	hub    *Hub
+	ticker int
+	events chan Event
+}
+
+//New returns an empty Hub.
+func New() *Hub {
+	return &Hub{subscribers: make(map[int]map[*Subscription]struct{})}
+}
+
+//Subscribe registers a new Subscription for the events of the ticker.
+func (h *Hub) Subscribe(tickerID int) *Subscription {
+	s := &Subscription{hub: h, ticker: tickerID, events: make(chan Event, BufferSize)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[tickerID] == nil {
+		h.subscribers[tickerID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[tickerID][s] = struct{}{}
+
+	return s
+}
+
+//Publish sends the event to all subscribers of its ticker without blocking.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subscribers[event.Ticker] {
+		select {
+		case s.events <- event:
+		default:
+			// the subscriber has to reload or resume from the last received event
+			s.close()
+		}
+	}
+}
+
+//Subscribers returns the number of subscribers for the ticker.
+func (h *Hub) Subscribers(tickerID int) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers[tickerID])
+}
+
+//Events returns the channel of the Subscription. It is closed when the Subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+//Close unregisters the Subscription. It is safe to call Close multiple times.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.close()
+}
+
+func (s *Subscription) close() {
+	subs, ok := s.hub.subscribers[s.ticker]
+	if !ok {
+		return
+	}
+	if _, ok := subs[s]; !ok {
+		return
+	}
+
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(s.hub.subscribers, s.ticker)
+	}
+	close(s.events)
+}
@@ -0,0 +1,52 @@
+package hub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/hub"
+)
+
+func TestPublish(t *testing.T) {
+	h := hub.New()
+
+	s1 := h.Subscribe(1)
+	s2 := h.Subscribe(2)
+	defer s2.Close()
+
+	assert.Equal(t, 1, h.Subscribers(1))
+
+	h.Publish(hub.Event{Type: hub.MessageCreated, Ticker: 1, Data: 1})
+
+	event := <-s1.Events()
+	assert.Equal(t, hub.MessageCreated, event.Type)
+	assert.Equal(t, 1, event.Data)
+	assert.Equal(t, 0, len(s2.Events()))
+
+	s1.Close()
+	s1.Close()
+
+	_, ok := <-s1.Events()
+	assert.False(t, ok)
+	assert.Equal(t, 0, h.Subscribers(1))
+}
+
+func TestPublishSlowSubscriber(t *testing.T) {
+	h := hub.New()
+
+	s := h.Subscribe(1)
+	defer s.Close()
+
+	for i := 0; i <= hub.BufferSize; i++ {
+		h.Publish(hub.Event{Type: hub.MessageCreated, Ticker: 1, Data: i})
+	}
+
+	assert.Equal(t, 0, h.Subscribers(1))
+
+	var received int
+	for range s.Events() {
+		received++
+	}
+	assert.Equal(t, hub.BufferSize, received)
+}
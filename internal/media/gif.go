@@ -0,0 +1,83 @@
+package media
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2C
+	gifTrailer         = 0x3B
+)
+
+//gifPixels returns the number of pixels of all frames of a GIF file. The blocks are walked without
+//decompressing the image data, so the size can be checked before the frames are decoded.
+func gifPixels(data []byte) (int, error) {
+	invalid := errors.New("invalid image: malformed gif")
+	if len(data) < 13 {
+		return 0, invalid
+	}
+
+	// header and logical screen descriptor, followed by the global color table
+	i := 13 + colorTableSize(data[10])
+	pixels := 0
+
+	for i < len(data) {
+		switch data[i] {
+		case gifTrailer:
+			return pixels, nil
+		case gifExtension:
+			if i+2 > len(data) {
+				return 0, invalid
+			}
+			i = skipSubBlocks(data, i+2)
+		case gifImageDescriptor:
+			if i+10 > len(data) {
+				return 0, invalid
+			}
+			width := int(binary.LittleEndian.Uint16(data[i+5 : i+7]))
+			height := int(binary.LittleEndian.Uint16(data[i+7 : i+9]))
+			pixels += width * height
+			if pixels > MaxPixels {
+				return pixels, nil
+			}
+
+			// local color table and the minimum code size of the image data
+			i = skipSubBlocks(data, i+10+colorTableSize(data[i+9])+1)
+		default:
+			return 0, invalid
+		}
+
+		if i < 0 {
+			return 0, invalid
+		}
+	}
+
+	// files without trailer are accepted by the decoder
+	return pixels, nil
+}
+
+//colorTableSize returns the size of the color table announced by the packed field of a descriptor.
+func colorTableSize(packed byte) int {
+	if packed&0x80 == 0 {
+		return 0
+	}
+
+	return 3 << (uint(packed&0x07) + 1)
+}
+
+//skipSubBlocks returns the position after the data sub-blocks starting at i or -1 when they are truncated.
+func skipSubBlocks(data []byte, i int) int {
+	for i < len(data) {
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i
+		}
+		i += size
+	}
+
+	return -1
+}
@@ -0,0 +1,199 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	//ThumbnailSize is the maximum width and height of a thumbnail.
+	ThumbnailSize = 400
+	//MaxPixels is the maximum number of pixels of an image or of all frames of an animation, larger images would
+	//exhaust the memory while decoding.
+	MaxPixels = 25000000
+
+	jpegQuality = 90
+)
+
+//Extensions maps the supported content types to their file extensions.
+var Extensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+//Image is a sanitized image with its thumbnail.
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+	Thumbnail   []byte
+}
+
+//Process validates the content type of the data and re-encodes the image. Re-encoding removes
+//all metadata (e.g. EXIF, comments) from the file. The orientation stored in the EXIF data of
+//JPEG files is applied to the pixels before the metadata is dropped.
+func Process(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := Extensions[contentType]; !ok {
+		return nil, errors.Errorf("unsupported content type %s", contentType)
+	}
+
+	// the dimensions are checked before the image is decoded
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid image")
+	}
+	pixels := config.Width * config.Height
+	// animations are limited by the pixels of all frames together
+	if contentType == "image/gif" && pixels <= MaxPixels {
+		pixels, err = gifPixels(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pixels > MaxPixels {
+		return nil, errors.Errorf("image exceeds %d pixels", MaxPixels)
+	}
+
+	img := &Image{ContentType: contentType}
+
+	var frame image.Image
+	var buf bytes.Buffer
+
+	switch contentType {
+	case "image/gif":
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid image")
+		}
+
+		// only the frames are written, comments and application extensions are dropped
+		err = gif.EncodeAll(&buf, &gif.GIF{Image: g.Image, Delay: g.Delay, LoopCount: g.LoopCount, Disposal: g.Disposal, Config: g.Config})
+		if err != nil {
+			return nil, err
+		}
+
+		frame = g.Image[0]
+	case "image/png":
+		src, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid image")
+		}
+
+		err = png.Encode(&buf, src)
+		if err != nil {
+			return nil, err
+		}
+
+		frame = src
+	case "image/jpeg":
+		src, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid image")
+		}
+
+		src = orient(src, orientation(data))
+
+		err = jpeg.Encode(&buf, src, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+
+		frame = src
+	}
+
+	img.Data = buf.Bytes()
+	img.Width = frame.Bounds().Dx()
+	img.Height = frame.Bounds().Dy()
+
+	thumbnail, err := encodeThumbnail(contentType, frame)
+	if err != nil {
+		return nil, err
+	}
+	img.Thumbnail = thumbnail
+
+	return img, nil
+}
+
+//ThumbnailExtension returns the file extension of the thumbnail. Thumbnails of GIF images are stored as PNG.
+func ThumbnailExtension(contentType string) string {
+	if contentType == "image/jpeg" {
+		return "jpg"
+	}
+
+	return "png"
+}
+
+func encodeThumbnail(contentType string, src image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	thumbnail := resize(src, ThumbnailSize)
+
+	var err error
+	if ThumbnailExtension(contentType) == "jpg" {
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, thumbnail)
+	}
+
+	return buf.Bytes(), err
+}
+
+//resize scales the image down to fit into a square of the given size by averaging the source pixels.
+func resize(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	rgba := image.NewNRGBA(b)
+	draw.Draw(rgba, b, src, b.Min, draw.Src)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := rgba.PixOffset(b.Min.X+sx, b.Min.Y+sy)
+					r += int(rgba.Pix[i])
+					g += int(rgba.Pix[i+1])
+					bl += int(rgba.Pix[i+2])
+					a += int(rgba.Pix[i+3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
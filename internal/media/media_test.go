@@ -0,0 +1,112 @@
+package media_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/media"
+)
+
+func TestProcessJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20)), nil)
+
+	// insert an APP1 segment with orientation 6 (rotate 90° clockwise) after the SOI marker
+	exif := []byte{
+		'E', 'x', 'i', 'f', 0, 0,
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+	data := append(append([]byte{0xFF, 0xD8}, segment...), buf.Bytes()[2:]...)
+
+	img, err := media.Process(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", img.ContentType)
+	assert.Equal(t, 20, img.Width)
+	assert.Equal(t, 40, img.Height)
+	assert.False(t, bytes.Contains(img.Data, []byte("Exif")))
+}
+
+func TestProcessThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	for x := 0; x < 1000; x++ {
+		for y := 0; y < 500; y++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, src)
+
+	img, err := media.Process(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", img.ContentType)
+	assert.Equal(t, 1000, img.Width)
+	assert.Equal(t, 500, img.Height)
+
+	thumbnail, err := png.Decode(bytes.NewReader(img.Thumbnail))
+	assert.Nil(t, err)
+	assert.Equal(t, media.ThumbnailSize, thumbnail.Bounds().Dx())
+	assert.Equal(t, media.ThumbnailSize/2, thumbnail.Bounds().Dy())
+
+	r, g, b, a := thumbnail.At(10, 10).RGBA()
+	assert.Equal(t, []uint32{0xFFFF, 0, 0, 0xFFFF}, []uint32{r, g, b, a})
+}
+
+func TestProcessInvalid(t *testing.T) {
+	_, err := media.Process([]byte("%PDF-1.4"))
+	assert.EqualError(t, err, "unsupported content type application/pdf")
+
+	_, err = media.Process([]byte("\x89PNG\x0D\x0A\x1A\x0A broken"))
+	assert.NotNil(t, err)
+}
+
+func TestProcessTooLarge(t *testing.T) {
+	// a gif header declaring a 65535x65535 image without any pixel data
+	data := []byte{'G', 'I', 'F', '8', '9', 'a', 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00}
+
+	_, err := media.Process(data)
+	assert.EqualError(t, err, "image exceeds 25000000 pixels")
+}
+
+func TestProcessAnimation(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	animation := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		animation.Image = append(animation.Image, image.NewPaletted(image.Rect(0, 0, 40, 20), palette))
+		animation.Delay = append(animation.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	gif.EncodeAll(&buf, animation)
+
+	img, err := media.Process(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "image/gif", img.ContentType)
+
+	g, err := gif.DecodeAll(bytes.NewReader(img.Data))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(g.Image))
+}
+
+func TestProcessTooManyFrames(t *testing.T) {
+	// a 1000x1000 gif with 30 frames, each without pixel data
+	data := []byte{'G', 'I', 'F', '8', '9', 'a', 0xE8, 0x03, 0xE8, 0x03, 0x00, 0x00, 0x00}
+	for i := 0; i < 30; i++ {
+		data = append(data, 0x2C, 0x00, 0x00, 0x00, 0x00, 0xE8, 0x03, 0xE8, 0x03, 0x00, 0x02, 0x00)
+	}
+	data = append(data, 0x3B)
+
+	_, err := media.Process(data)
+	assert.EqualError(t, err, "image exceeds 25000000 pixels")
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"net"
 	"path/filepath"
 	"strings"
 
@@ -11,6 +12,13 @@ import (
 
 var Config *config
 
+//Strategies to resolve the domain of the requested ticker, see DomainResolution.
+const (
+	DomainResolutionOrigin        = "origin"
+	DomainResolutionHost          = "host"
+	DomainResolutionForwardedHost = "forwarded_host"
+)
+
 type config struct {
 	Listen                string `mapstructure:"listen"`
 	LogLevel              string `mapstructure:"log_level"`
@@ -19,7 +27,19 @@ type config struct {
 	Database              string `mapstructure:"database"`
 	TwitterConsumerKey    string `mapstructure:"twitter_consumer_key"`
 	TwitterConsumerSecret string `mapstructure:"twitter_consumer_secret"`
+	TelegramBotToken      string `mapstructure:"telegram_bot_token"`
+	TelegramAPIURL        string `mapstructure:"telegram_api_url"`
+	TelegramInbound       bool   `mapstructure:"telegram_inbound"`
 	MetricsListen         string `mapstructure:"metrics_listen"`
+	UploadPath            string `mapstructure:"upload_path"`
+	UploadURL             string `mapstructure:"upload_url"`
+	UploadMaxSize         int64  `mapstructure:"upload_max_size"`
+	//DomainResolution lists the strategies to resolve the ticker domain in the order they are tried.
+	DomainResolution []string `mapstructure:"domain_resolution"`
+	//TrustedProxies lists the addresses or CIDRs of proxies whose X-Forwarded-Host header is trusted.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	//SecretGenerated is true when no secret is configured and a random one is used, which changes with every start.
+	SecretGenerated bool `mapstructure:"-"`
 }
 
 //NewConfig returns config with default values.
@@ -27,12 +47,17 @@ func NewConfig() *config {
 	secret, _ := password.Generate(64, 12, 12, false, false)
 
 	return &config{
-		Listen:        ":8080",
-		LogLevel:      "debug",
-		Initiator:     "admin@systemli.org",
-		Secret:        secret,
-		Database:      "ticker.db",
-		MetricsListen: ":8181",
+		Listen:           ":8080",
+		LogLevel:         "debug",
+		Initiator:        "admin@systemli.org",
+		Secret:           secret,
+		Database:         "ticker.db",
+		MetricsListen:    ":8181",
+		TelegramAPIURL:   "https://api.telegram.org",
+		UploadPath:       "uploads",
+		UploadURL:        "http://localhost:8080",
+		UploadMaxSize:    10 << 20,
+		DomainResolution: []string{DomainResolutionOrigin},
 	}
 }
 
@@ -41,9 +66,45 @@ func (c *config) TwitterEnabled() bool {
 	return c.TwitterConsumerKey != "" && c.TwitterConsumerSecret != ""
 }
 
+//TelegramEnabled returns true if the bot token is not empty.
+func (c *config) TelegramEnabled() bool {
+	return c.TelegramBotToken != ""
+}
+
+//TelegramInboundEnabled returns true if messages should be received from the telegram bot.
+func (c *config) TelegramInboundEnabled() bool {
+	return c.TelegramEnabled() && c.TelegramInbound
+}
+
+//TwoFactorAvailable returns true if the secret is configured. The two-factor secrets are encrypted with it,
+//a generated secret would make them unusable after a restart.
+func (c *config) TwoFactorAvailable() bool {
+	return !c.SecretGenerated
+}
+
+//TrustedProxy returns true if the ip belongs to one of the trusted proxies.
+func (c *config) TrustedProxy(ip net.IP) bool {
+	for _, proxy := range c.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip.Equal(net.ParseIP(proxy)) {
+				return true
+			}
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 //LoadConfig loads config from file.
 func LoadConfig(path string) *config {
 	c := NewConfig()
+	secret := c.Secret
 	viper.SetEnvPrefix("ticker")
 	viper.AutomaticEnv()
 
@@ -55,6 +116,14 @@ func LoadConfig(path string) *config {
 	viper.SetDefault("metrics_listen", c.MetricsListen)
 	viper.SetDefault("twitter_consumer_key", "")
 	viper.SetDefault("twitter_consumer_secret", "")
+	viper.SetDefault("telegram_bot_token", "")
+	viper.SetDefault("telegram_api_url", c.TelegramAPIURL)
+	viper.SetDefault("telegram_inbound", false)
+	viper.SetDefault("upload_path", c.UploadPath)
+	viper.SetDefault("upload_url", c.UploadURL)
+	viper.SetDefault("upload_max_size", c.UploadMaxSize)
+	viper.SetDefault("domain_resolution", c.DomainResolution)
+	viper.SetDefault("trusted_proxies", []string{})
 
 	dir, file := filepath.Split(path)
 	// use current directory as default
@@ -76,6 +145,7 @@ func LoadConfig(path string) *config {
 	if err != nil {
 		panic(fmt.Errorf("unable to decode into struct, %v", err))
 	}
+	c.SecretGenerated = c.Secret == secret
 
 	Config = c
 	return Config
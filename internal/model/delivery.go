@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+const (
+	DeliveryStatePending = `pending`
+	DeliveryStateSent    = `sent`
+	DeliveryStateFailed  = `failed`
+)
+
+//BridgeDelivery represents the state of a message for a single bridge.
+type BridgeDelivery struct {
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	Message      int       `storm:"index"`
+	Ticker       int       `storm:"index"`
+	Bridge       string
+	State        string `storm:"index"`
+	Attempts     int
+	LastError    string
+	NextAttempt  time.Time
+}
+
+type BridgeDeliveryResponse struct {
+	Bridge      string    `json:"bridge"`
+	State       string    `json:"state"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+//NewBridgeDelivery creates a pending BridgeDelivery for the message.
+func NewBridgeDelivery(message Message, bridge string) *BridgeDelivery {
+	return &BridgeDelivery{
+		CreationDate: time.Now(),
+		Message:      message.ID,
+		Ticker:       message.Ticker,
+		Bridge:       bridge,
+		State:        DeliveryStatePending,
+		NextAttempt:  time.Now(),
+	}
+}
+
+//
+func NewBridgeDeliveriesResponse(deliveries []BridgeDelivery) []*BridgeDeliveryResponse {
+	dr := []*BridgeDeliveryResponse{}
+	for _, delivery := range deliveries {
+		dr = append(dr, &BridgeDeliveryResponse{
+			Bridge:      delivery.Bridge,
+			State:       delivery.State,
+			Attempts:    delivery.Attempts,
+			LastError:   delivery.LastError,
+			NextAttempt: delivery.NextAttempt,
+		})
+	}
+
+	return dr
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	GeometryTypePoint   = `Point`
+	GeometryTypePolygon = `Polygon`
+)
+
+//Geometry is a GeoJSON geometry (RFC 7946) attached to a message.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+//Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         int                    `json:"id"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+//FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+//Validate checks the type and coordinates of the geometry.
+func (g *Geometry) Validate() error {
+	switch g.Type {
+	case GeometryTypePoint:
+		var position []float64
+		if err := json.Unmarshal(g.Coordinates, &position); err != nil {
+			return errors.New("Geometry: invalid coordinates")
+		}
+
+		return validatePosition(position)
+	case GeometryTypePolygon:
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil || len(rings) == 0 {
+			return errors.New("Geometry: invalid coordinates")
+		}
+
+		for _, ring := range rings {
+			if len(ring) < 4 {
+				return errors.New("Geometry: a polygon ring needs at least four positions")
+			}
+
+			for _, position := range ring {
+				if err := validatePosition(position); err != nil {
+					return err
+				}
+			}
+
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				return errors.New("Geometry: a polygon ring must be closed")
+			}
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("Geometry: unsupported type %s", g.Type)
+}
+
+//NewFeatureCollection returns a FeatureCollection with all located messages.
+func NewFeatureCollection(messages []Message) *FeatureCollection {
+	fc := &FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []*Feature{},
+	}
+
+	for _, message := range messages {
+		if message.Geometry == nil {
+			continue
+		}
+
+		fc.Features = append(fc.Features, &Feature{
+			Type:     "Feature",
+			ID:       message.ID,
+			Geometry: message.Geometry,
+			Properties: map[string]interface{}{
+				"id":            message.ID,
+				"text":          message.Text,
+				"creation_date": message.CreationDate,
+			},
+		})
+	}
+
+	return fc
+}
+
+func validatePosition(position []float64) error {
+	if len(position) < 2 {
+		return errors.New("Geometry: a position needs longitude and latitude")
+	}
+
+	if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+		return errors.New("Geometry: position out of range")
+	}
+
+	return nil
+}
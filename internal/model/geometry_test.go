@@ -0,0 +1,33 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/model"
+)
+
+func TestGeometryValidate(t *testing.T) {
+	point := model.Geometry{Type: model.GeometryTypePoint, Coordinates: json.RawMessage(`[13.4, 52.5]`)}
+	assert.Nil(t, point.Validate())
+
+	point.Coordinates = json.RawMessage(`[13.4, 100]`)
+	assert.EqualError(t, point.Validate(), "Geometry: position out of range")
+
+	point.Coordinates = json.RawMessage(`"13.4, 52.5"`)
+	assert.EqualError(t, point.Validate(), "Geometry: invalid coordinates")
+
+	polygon := model.Geometry{Type: model.GeometryTypePolygon, Coordinates: json.RawMessage(`[[[13.4, 52.5], [13.5, 52.5], [13.5, 52.6], [13.4, 52.5]]]`)}
+	assert.Nil(t, polygon.Validate())
+
+	polygon.Coordinates = json.RawMessage(`[[[13.4, 52.5], [13.5, 52.5], [13.5, 52.6], [13.4, 52.6]]]`)
+	assert.EqualError(t, polygon.Validate(), "Geometry: a polygon ring must be closed")
+
+	polygon.Coordinates = json.RawMessage(`[[[13.4, 52.5], [13.5, 52.5], [13.4, 52.5]]]`)
+	assert.EqualError(t, polygon.Validate(), "Geometry: a polygon ring needs at least four positions")
+
+	line := model.Geometry{Type: "LineString", Coordinates: json.RawMessage(`[[13.4, 52.5], [13.5, 52.5]]`)}
+	assert.EqualError(t, line.Validate(), "Geometry: unsupported type LineString")
+}
@@ -2,7 +2,10 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/systemli/ticker/internal/util"
 )
 
 //Message represents a single message
@@ -11,63 +14,217 @@ type Message struct {
 	CreationDate time.Time `storm:"index"`
 	Ticker       int       `storm:"index"`
 	Text         string
+	Severity     string
+	Pinned       bool `storm:"index"`
 	Tweet        Tweet
-	//TODO: Geolocation, Facebook-ID
+	Mastodon     MastodonStatus
+	Telegram     TelegramMessage
+	Geometry     *Geometry
+	Attachments  []Attachment
+	EditedAt     *time.Time
+	EditedBy     int
+	PublishAt    time.Time
+	Scheduled    bool `storm:"index"`
+	Author       int
+	//Draft messages wait for the approval of another ticker member.
+	Draft bool `storm:"index"`
+	//Revised marks published messages which became drafts by an edit, their approval edits the published posts.
+	Revised         bool
+	Rejected        bool
+	Reviewer        int
+	RejectionReason string
+	//TODO: Facebook-ID
 }
 
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityAlert   = "alert"
+)
+
+//Severities contains all valid message severities.
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityAlert}
+
 //
 type Tweet struct {
 	ID       string
 	UserName string
+	//Replies holds the IDs of the follow-up tweets when the message was split into a thread.
+	Replies []string
+}
+
+//MastodonStatus holds the reference to the published mastodon status.
+type MastodonStatus struct {
+	ID  string
+	URL string
+}
+
+//TelegramMessage holds the reference to the published telegram message.
+type TelegramMessage struct {
+	ChatID    string
+	MessageID int
+	//MediaIDs holds the IDs of additional messages with the attachments.
+	MediaIDs []int
 }
 
 type MessageResponse struct {
-	ID           int       `json:"id"`
-	CreationDate time.Time `json:"creation_date"`
-	Text         string    `json:"text"`
-	Ticker       int       `json:"ticker"`
-	TweetID      string    `json:"tweet_id"`
-	TweetUser    string    `json:"tweet_user"`
+	ID              int                   `json:"id"`
+	CreationDate    time.Time             `json:"creation_date"`
+	Text            string                `json:"text"`
+	Severity        string                `json:"severity"`
+	Pinned          bool                  `json:"pinned"`
+	Ticker          int                   `json:"ticker"`
+	TweetID         string                `json:"tweet_id"`
+	TweetUser       string                `json:"tweet_user"`
+	TweetReplies    []string              `json:"tweet_replies,omitempty"`
+	MastodonID      string                `json:"mastodon_id"`
+	MastodonURL     string                `json:"mastodon_url"`
+	TelegramID      int                   `json:"telegram_message_id"`
+	Geometry        *Geometry             `json:"geometry"`
+	Attachments     []*AttachmentResponse `json:"attachments"`
+	EditedAt        *time.Time            `json:"edited_at"`
+	Scheduled       bool                  `json:"scheduled"`
+	PublishAt       *time.Time            `json:"publish_at"`
+	Author          int                   `json:"author"`
+	Draft           bool                  `json:"draft"`
+	Revised         bool                  `json:"revised"`
+	Rejected        bool                  `json:"rejected"`
+	Reviewer        int                   `json:"reviewer"`
+	RejectionReason string                `json:"rejection_reason"`
+	//Deliveries are only included in the admin responses.
+	Deliveries []*BridgeDeliveryResponse `json:"deliveries,omitempty"`
+}
+
+//PublicMessageResponse is the representation of a message in the public timeline. It must not contain
+//the workflow state or internal IDs besides the message ID, which is used for paging.
+type PublicMessageResponse struct {
+	ID           int                   `json:"id"`
+	CreationDate time.Time             `json:"creation_date"`
+	Text         string                `json:"text"`
+	Severity     string                `json:"severity"`
+	Pinned       bool                  `json:"pinned"`
+	TweetID      string                `json:"tweet_id"`
+	TweetUser    string                `json:"tweet_user"`
+	MastodonURL  string                `json:"mastodon_url"`
+	Geometry     *Geometry             `json:"geometry"`
+	Attachments  []*AttachmentResponse `json:"attachments"`
+	EditedAt     *time.Time            `json:"edited_at"`
 }
 
 //NewMessage creates new Message
 func NewMessage() *Message {
 	return &Message{
 		CreationDate: time.Now(),
+		Severity:     SeverityInfo,
+	}
+}
+
+//ValidateSeverity checks if the severity is one of the known Severities.
+func ValidateSeverity(severity string) error {
+	for _, s := range Severities {
+		if s == severity {
+			return nil
+		}
 	}
+
+	return fmt.Errorf("Severity: must be one of %s", strings.Join(Severities, ", "))
 }
 
 //
 func NewMessageResponse(message Message) *MessageResponse {
-	return &MessageResponse{
+	mr := &MessageResponse{
+		ID:              message.ID,
+		CreationDate:    message.CreationDate,
+		Text:            message.Text,
+		Severity:        message.Severity,
+		Pinned:          message.Pinned,
+		Ticker:          message.Ticker,
+		TweetID:         message.Tweet.ID,
+		TweetUser:       message.Tweet.UserName,
+		TweetReplies:    message.Tweet.Replies,
+		MastodonID:      message.Mastodon.ID,
+		MastodonURL:     message.Mastodon.URL,
+		TelegramID:      message.Telegram.MessageID,
+		Geometry:        message.Geometry,
+		Attachments:     NewAttachmentsResponse(message.Attachments),
+		EditedAt:        message.EditedAt,
+		Scheduled:       message.Scheduled,
+		Author:          message.Author,
+		Draft:           message.Draft,
+		Revised:         message.Revised,
+		Rejected:        message.Rejected,
+		Reviewer:        message.Reviewer,
+		RejectionReason: message.RejectionReason,
+	}
+
+	// messages without severity were created before severities existed
+	if mr.Severity == "" {
+		mr.Severity = SeverityInfo
+	}
+
+	if message.Scheduled {
+		publishAt := message.PublishAt
+		mr.PublishAt = &publishAt
+	}
+
+	return mr
+}
+
+//
+func NewMessagesResponse(messages []Message) []*MessageResponse {
+	var mr []*MessageResponse
+
+	for _, message := range messages {
+		mr = append(mr, NewMessageResponse(message))
+	}
+
+	return mr
+}
+
+//NewPublicMessageResponse returns the public representation of the message.
+func NewPublicMessageResponse(message Message) *PublicMessageResponse {
+	severity := message.Severity
+	if severity == "" {
+		severity = SeverityInfo
+	}
+
+	return &PublicMessageResponse{
 		ID:           message.ID,
 		CreationDate: message.CreationDate,
 		Text:         message.Text,
-		Ticker:       message.Ticker,
+		Severity:     severity,
+		Pinned:       message.Pinned,
 		TweetID:      message.Tweet.ID,
 		TweetUser:    message.Tweet.UserName,
+		MastodonURL:  message.Mastodon.URL,
+		Geometry:     message.Geometry,
+		Attachments:  NewAttachmentsResponse(message.Attachments),
+		EditedAt:     message.EditedAt,
 	}
 }
 
-//
-func NewMessagesResponse(messages []Message) []*MessageResponse {
-	var mr []*MessageResponse
+//NewPublicMessagesResponse returns the public representation of the messages.
+func NewPublicMessagesResponse(messages []Message) []*PublicMessageResponse {
+	var mr []*PublicMessageResponse
 
 	for _, message := range messages {
-		mr = append(mr, NewMessageResponse(message))
+		mr = append(mr, NewPublicMessageResponse(message))
 	}
 
 	return mr
 }
 
-//PrepareTweet prepares the message for Twitter.
-func (m *Message) PrepareTweet(ticker *Ticker) string {
-	tweet := m.Text
+//PrepareTweet prepares the message for Twitter. Long messages are split into a thread.
+func (m *Message) PrepareTweet(ticker *Ticker) []string {
+	return util.SplitTweet(m.PrepareText(ticker))
+}
+
+//PrepareText prepares the message text for bridges.
+func (m *Message) PrepareText(ticker *Ticker) string {
+	text := m.Text
 	if ticker.PrependTime {
-		tweet = fmt.Sprintf(`%.2d:%.2d %s`, m.CreationDate.Hour(), m.CreationDate.Minute(), tweet)
+		text = fmt.Sprintf(`%.2d:%.2d %s`, m.CreationDate.Hour(), m.CreationDate.Minute(), text)
 	}
 
-	//TODO: Check length, split long tweets
-
-	return tweet
+	return text
 }
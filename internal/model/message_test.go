@@ -3,6 +3,7 @@ package model_test
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/systemli/ticker/internal/model"
+	"strings"
 	"testing"
 	"time"
 )
@@ -13,9 +14,17 @@ func TestPrepareTweet(t *testing.T) {
 	message.CreationDate, _ = time.Parse(time.RFC3339, "2012-11-01T22:08:41+00:00")
 	message.Text = "example"
 
-	assert.Equal(t, "example", message.PrepareTweet(ticker))
+	assert.Equal(t, []string{"example"}, message.PrepareTweet(ticker))
 
 	ticker.PrependTime = true
 
-	assert.Equal(t, "22:08 example", message.PrepareTweet(ticker))
+	assert.Equal(t, []string{"22:08 example"}, message.PrepareTweet(ticker))
+
+	message.Text = strings.Repeat("example ", 40)
+
+	tweets := message.PrepareTweet(ticker)
+	assert.Equal(t, 2, len(tweets))
+	assert.True(t, strings.HasPrefix(tweets[0], "22:08 example"))
+	assert.True(t, strings.HasSuffix(tweets[0], " 1/2"))
+	assert.True(t, strings.HasSuffix(tweets[1], " 2/2"))
 }
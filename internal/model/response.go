@@ -5,12 +5,26 @@ const (
 	ErrorCodeNotFound                = 1001
 	ErrorCodeCredentials             = 1002
 	ErrorCodeInsufficientPermissions = 1003
+	ErrorCodeTwoFactor               = 1004
 
 	ErrorInsufficientPermissions = "insufficient permissions"
 	ErrorUserIdentifierMissing   = "user identifier not found"
 	ErrorUserNotFound            = "user not found"
 	ErrorTickerNotFound          = "ticker not found"
 	ErrorSettingNotFound         = "setting not found"
+	ErrorWebhookNotFound         = "webhook not found"
+	ErrorMessageNotFound         = "message not found"
+	ErrorUploadNotFound          = "upload not found"
+	ErrorMessageNotDraft         = "message is not a draft"
+	ErrorApprovalByAuthor        = "messages can not be reviewed by their author"
+	ErrorInvalidAPIToken         = "invalid api token"
+	ErrorAPITokenNotFound        = "api token not found"
+	ErrorTwoFactorRequired       = "two-factor authentication required"
+	ErrorOTPRequired             = "one-time password required"
+	ErrorInvalidOTP              = "invalid one-time password"
+	ErrorTOTPNotEnrolled         = "two-factor authentication is not enrolled"
+	ErrorTOTPEnabled             = "two-factor authentication is already enabled"
+	ErrorTOTPUnavailable         = "two-factor authentication requires a configured secret"
 
 	ResponseSuccess = `success`
 	ResponseError   = `error`
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+//MessageRevision holds a previous version of an edited message.
+type MessageRevision struct {
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	Message      int       `storm:"index"`
+	Ticker       int       `storm:"index"`
+	User         int
+	Text         string
+}
+
+type MessageRevisionResponse struct {
+	ID           int       `json:"id"`
+	CreationDate time.Time `json:"creation_date"`
+	User         int       `json:"user"`
+	Text         string    `json:"text"`
+}
+
+//NewMessageRevision creates a revision with the current version of the message.
+func NewMessageRevision(message Message) *MessageRevision {
+	revision := &MessageRevision{
+		CreationDate: message.CreationDate,
+		Message:      message.ID,
+		Ticker:       message.Ticker,
+		User:         message.EditedBy,
+		Text:         message.Text,
+	}
+
+	if message.EditedAt != nil {
+		revision.CreationDate = *message.EditedAt
+	}
+	// the original text was written by the author
+	if revision.User == 0 {
+		revision.User = message.Author
+	}
+
+	return revision
+}
+
+//
+func NewMessageRevisionsResponse(revisions []MessageRevision) []*MessageRevisionResponse {
+	rr := []*MessageRevisionResponse{}
+	for _, revision := range revisions {
+		rr = append(rr, &MessageRevisionResponse{
+			ID:           revision.ID,
+			CreationDate: revision.CreationDate,
+			User:         revision.User,
+			Text:         revision.Text,
+		})
+	}
+
+	return rr
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+//Roles of a user on a ticker.
+const (
+	RoleOwner       = "owner"
+	RoleEditor      = "editor"
+	RoleContributor = "contributor"
+	RoleViewer      = "viewer"
+)
+
+//DefaultRole is used for members of a ticker without an explicit role.
+const DefaultRole = RoleEditor
+
+//Permissions on a ticker.
+const (
+	PermissionReadTicker      = "ticker.read"
+	PermissionWriteTicker     = "ticker.write"
+	PermissionManageUsers     = "ticker.users"
+	PermissionReadMessages    = "messages.read"
+	PermissionCreateMessages  = "messages.create"
+	PermissionPublishMessages = "messages.publish"
+	PermissionEditMessages    = "messages.edit"
+	PermissionApproveMessages = "messages.approve"
+)
+
+//Roles lists all roles from the most to the least privileged.
+var Roles = []string{RoleOwner, RoleEditor, RoleContributor, RoleViewer}
+
+//RolePermissions is the permission matrix of the roles. Messages created without
+//PermissionPublishMessages are stored as drafts and have to be approved.
+var RolePermissions = map[string][]string{
+	RoleOwner: {
+		PermissionReadTicker, PermissionWriteTicker, PermissionManageUsers,
+		PermissionReadMessages, PermissionCreateMessages, PermissionPublishMessages, PermissionEditMessages, PermissionApproveMessages,
+	},
+	RoleEditor: {
+		PermissionReadTicker,
+		PermissionReadMessages, PermissionCreateMessages, PermissionPublishMessages, PermissionEditMessages, PermissionApproveMessages,
+	},
+	RoleContributor: {
+		PermissionReadTicker,
+		PermissionReadMessages, PermissionCreateMessages,
+	},
+	RoleViewer: {
+		PermissionReadTicker,
+		PermissionReadMessages,
+	},
+}
+
+//ValidateRole returns an error when the role is unknown.
+func ValidateRole(role string) error {
+	if !validRole(role) {
+		return errors.New("Role: must be one of " + strings.Join(Roles, ", "))
+	}
+
+	return nil
+}
+
+func validRole(role string) bool {
+	_, ok := RolePermissions[role]
+
+	return ok
+}
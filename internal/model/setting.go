@@ -3,6 +3,8 @@ package model
 const (
 	SettingInactiveName           = `inactive_settings`
 	SettingRefreshInterval        = `refresh_interval`
+	SettingEnforceTwoFactor       = `enforce_two_factor`
+	SettingTickerRolesMigrated    = `ticker_roles_migrated`
 	SettingInactiveHeadline       = `The ticker is currently inactive.`
 	SettingInactiveSubHeadline    = `Please contact us if you want to use it.`
 	SettingInactiveDescription    = `...`
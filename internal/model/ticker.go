@@ -1,8 +1,10 @@
 package model
 
 import (
-	"github.com/dghubble/go-twitter/twitter"
+	"strings"
 	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
 )
 
 //Ticker represents the structure of an Ticker configuration
@@ -10,13 +12,19 @@ type Ticker struct {
 	ID           int       `storm:"id,increment"`
 	CreationDate time.Time `storm:"index"`
 	Domain       string    `storm:"unique"`
-	Title        string
-	Description  string
-	Active       bool
-	PrependTime  bool `json:"prepend_time"`
-	Hashtags     []string
-	Information  Information
-	Twitter      Twitter
+	//Aliases are further domains under which the ticker is reachable, Domain stays the primary domain.
+	Aliases     []string
+	Title       string
+	Description string
+	Active      bool
+	PrependTime bool `json:"prepend_time"`
+	Hashtags    []string
+	//RequireApproval keeps new messages as drafts until another member approves them.
+	RequireApproval bool
+	Information     Information
+	Twitter         Twitter
+	Mastodon        Mastodon
+	Telegram        Telegram
 }
 
 //Information holds some meta information for Ticker
@@ -36,17 +44,57 @@ type Twitter struct {
 	User   twitter.User
 }
 
+//Mastodon holds all required mastodon information.
+type Mastodon struct {
+	Active bool
+	Server string
+	Token  string
+	User   MastodonUser
+}
+
+//MastodonUser holds the account information of the connected mastodon user.
+type MastodonUser struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	URL         string `json:"url"`
+	Avatar      string `json:"avatar"`
+}
+
+//Telegram holds all required telegram information.
+type Telegram struct {
+	Active    bool
+	ChatID    string
+	ChatTitle string
+}
+
 type TickerResponse struct {
-	ID           int                 `json:"id"`
+	ID              int                 `json:"id"`
+	CreationDate    time.Time           `json:"creation_date"`
+	Domain          string              `json:"domain"`
+	Aliases         []string            `json:"aliases"`
+	Title           string              `json:"title"`
+	Description     string              `json:"description"`
+	Active          bool                `json:"active"`
+	PrependTime     bool                `json:"prepend_time"`
+	Hashtags        []string            `json:"hashtags"`
+	RequireApproval bool                `json:"require_approval"`
+	Information     InformationResponse `json:"information"`
+	Twitter         TwitterResponse     `json:"twitter"`
+	Mastodon        MastodonResponse    `json:"mastodon"`
+	Telegram        TelegramResponse    `json:"telegram"`
+	//Role and Permissions of the requesting user, not set for webhooks and socket events.
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+//PublicTickerResponse is the representation of a ticker for anonymous visitors. It must not contain
+//internal IDs or the state of the bridges.
+type PublicTickerResponse struct {
 	CreationDate time.Time           `json:"creation_date"`
-	Domain       string              `json:"domain"`
 	Title        string              `json:"title"`
 	Description  string              `json:"description"`
-	Active       bool                `json:"active"`
-	PrependTime  bool                `json:"prepend_time"`
-	Hashtags     []string            `json:"hashtags"`
 	Information  InformationResponse `json:"information"`
-	Twitter      TwitterResponse     `json:"twitter"`
 }
 
 type InformationResponse struct {
@@ -66,6 +114,23 @@ type TwitterResponse struct {
 	ImageURL    string `json:"image_url"`
 }
 
+type MastodonResponse struct {
+	Active     bool   `json:"active"`
+	Connected  bool   `json:"connected"`
+	Server     string `json:"server"`
+	Name       string `json:"name"`
+	ScreenName string `json:"screen_name"`
+	URL        string `json:"url"`
+	ImageURL   string `json:"image_url"`
+}
+
+type TelegramResponse struct {
+	Active    bool   `json:"active"`
+	Connected bool   `json:"connected"`
+	ChatID    string `json:"chat_id"`
+	ChatTitle string `json:"chat_title"`
+}
+
 //NewTicker creates new Ticker
 func NewTicker() *Ticker {
 	return &Ticker{
@@ -79,22 +144,19 @@ func (t *Ticker) Reset() {
 	t.Description = ""
 	t.PrependTime = false
 	t.Hashtags = []string{}
+	t.RequireApproval = false
 	t.Information = Information{}
 	t.Twitter.Secret = ""
 	t.Twitter.Token = ""
 	t.Twitter.Active = false
 	t.Twitter.User = twitter.User{}
+	t.Mastodon = Mastodon{}
+	t.Telegram = Telegram{}
 }
 
 //
 func NewTickerResponse(ticker *Ticker) *TickerResponse {
-	info := InformationResponse{
-		Author:   ticker.Information.Author,
-		URL:      ticker.Information.URL,
-		Email:    ticker.Information.Email,
-		Twitter:  ticker.Information.Twitter,
-		Facebook: ticker.Information.Facebook,
-	}
+	info := newInformationResponse(ticker.Information)
 
 	tw := TwitterResponse{
 		Active:      ticker.Twitter.Active,
@@ -105,17 +167,58 @@ func NewTickerResponse(ticker *Ticker) *TickerResponse {
 		ImageURL:    ticker.Twitter.User.ProfileImageURLHttps,
 	}
 
+	m := MastodonResponse{
+		Active:     ticker.Mastodon.Active,
+		Connected:  ticker.Mastodon.Connected(),
+		Server:     ticker.Mastodon.Server,
+		Name:       ticker.Mastodon.User.DisplayName,
+		ScreenName: ticker.Mastodon.User.Username,
+		URL:        ticker.Mastodon.User.URL,
+		ImageURL:   ticker.Mastodon.User.Avatar,
+	}
+
+	tg := TelegramResponse{
+		Active:    ticker.Telegram.Active,
+		Connected: ticker.Telegram.Connected(),
+		ChatID:    ticker.Telegram.ChatID,
+		ChatTitle: ticker.Telegram.ChatTitle,
+	}
+
 	return &TickerResponse{
-		ID:           ticker.ID,
+		ID:              ticker.ID,
+		CreationDate:    ticker.CreationDate,
+		Domain:          ticker.Domain,
+		Aliases:         ticker.Aliases,
+		Title:           ticker.Title,
+		Description:     ticker.Description,
+		Active:          ticker.Active,
+		PrependTime:     ticker.PrependTime,
+		Hashtags:        ticker.Hashtags,
+		RequireApproval: ticker.RequireApproval,
+		Information:     info,
+		Twitter:         tw,
+		Mastodon:        m,
+		Telegram:        tg,
+	}
+}
+
+func newInformationResponse(information Information) InformationResponse {
+	return InformationResponse{
+		Author:   information.Author,
+		URL:      information.URL,
+		Email:    information.Email,
+		Twitter:  information.Twitter,
+		Facebook: information.Facebook,
+	}
+}
+
+//NewPublicTickerResponse returns the public representation of the ticker.
+func NewPublicTickerResponse(ticker *Ticker) *PublicTickerResponse {
+	return &PublicTickerResponse{
 		CreationDate: ticker.CreationDate,
-		Domain:       ticker.Domain,
 		Title:        ticker.Title,
 		Description:  ticker.Description,
-		Active:       ticker.Active,
-		PrependTime:  ticker.PrependTime,
-		Hashtags:     ticker.Hashtags,
-		Information:  info,
-		Twitter:      tw,
+		Information:  newInformationResponse(ticker.Information),
 	}
 }
 
@@ -130,7 +233,33 @@ func NewTickersResponse(tickers []*Ticker) []*TickerResponse {
 	return tr
 }
 
+//Domains returns the primary domain followed by all aliases of the ticker.
+func (t *Ticker) Domains() []string {
+	return append([]string{t.Domain}, t.Aliases...)
+}
+
+//NormalizeDomain returns the domain in lower case without port and "www." prefix.
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "www.")
+	if i := strings.Index(domain, ":"); i >= 0 {
+		domain = domain[:i]
+	}
+
+	return domain
+}
+
 //Connected returns true when twitter can be used.
 func (tw *Twitter) Connected() bool {
 	return tw.Token != "" && tw.Secret != ""
 }
+
+//Connected returns true when mastodon can be used.
+func (m *Mastodon) Connected() bool {
+	return m.Server != "" && m.Token != ""
+}
+
+//Connected returns true when a telegram chat is configured.
+func (tg *Telegram) Connected() bool {
+	return tg.ChatID != ""
+}
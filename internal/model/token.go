@@ -0,0 +1,159 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+//APITokenPrefix marks API tokens, so they can be told apart from JWTs in the Authorization header.
+const APITokenPrefix = "ticker_"
+
+//Scopes of an API token.
+const (
+	ScopeTickersRead   = "tickers:read"
+	ScopeTickersWrite  = "tickers:write"
+	ScopeMessagesRead  = "messages:read"
+	ScopeMessagesWrite = "messages:write"
+)
+
+//Scopes lists all scopes an API token can be restricted to.
+var Scopes = []string{ScopeTickersRead, ScopeTickersWrite, ScopeMessagesRead, ScopeMessagesWrite}
+
+//ScopePermissions maps the scopes to the permissions they grant. Approving messages
+//is left to humans and not granted by any scope.
+var ScopePermissions = map[string][]string{
+	ScopeTickersRead:   {PermissionReadTicker},
+	ScopeTickersWrite:  {PermissionReadTicker, PermissionWriteTicker, PermissionManageUsers},
+	ScopeMessagesRead:  {PermissionReadTicker, PermissionReadMessages},
+	ScopeMessagesWrite: {PermissionReadTicker, PermissionReadMessages, PermissionCreateMessages, PermissionPublishMessages, PermissionEditMessages},
+}
+
+//APIToken is a long-lived token of a user for bots and automation. Only the hash of the token is stored.
+type APIToken struct {
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	User         int       `storm:"index"`
+	Name         string
+	Hash         string `storm:"unique"`
+	Prefix       string
+	Scopes       []string
+	Tickers      []int
+	LastUsedAt   *time.Time
+}
+
+type APITokenResponse struct {
+	ID           int        `json:"id"`
+	CreationDate time.Time  `json:"creation_date"`
+	User         int        `json:"user"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Scopes       []string   `json:"scopes"`
+	Tickers      []int      `json:"tickers"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	Token        string     `json:"token,omitempty"`
+}
+
+//NewAPIToken creates a new APIToken for the user and returns it together with the plaintext token,
+//which can't be recovered later.
+func NewAPIToken(user int, name string) (*APIToken, string) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	token := APITokenPrefix + hex.EncodeToString(b)
+
+	return &APIToken{
+		CreationDate: time.Now(),
+		User:         user,
+		Name:         name,
+		Hash:         HashAPIToken(token),
+		Prefix:       token[:len(APITokenPrefix)+8],
+		Scopes:       []string{},
+		Tickers:      []int{},
+	}, token
+}
+
+//HashAPIToken returns the hash under which the token is stored.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+//IsAPIToken returns true when the token looks like an API token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+//Restricted returns true when the token is limited to scopes or tickers.
+func (t *APIToken) Restricted() bool {
+	return len(t.Scopes) > 0 || len(t.Tickers) > 0
+}
+
+//Allows returns true when the restrictions of the token permit the permission on the ticker.
+//The permissions of the user are checked separately.
+func (t *APIToken) Allows(tickerID int, permission string) bool {
+	if len(t.Tickers) > 0 {
+		found := false
+		for _, id := range t.Tickers {
+			if id == tickerID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(t.Scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range t.Scopes {
+		for _, p := range ScopePermissions[scope] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//ValidateScopes returns an error when one of the scopes is unknown.
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if _, ok := ScopePermissions[scope]; !ok {
+			return errors.New("Scopes: must be one of " + strings.Join(Scopes, ", "))
+		}
+	}
+
+	return nil
+}
+
+//
+func NewAPITokenResponse(token APIToken) *APITokenResponse {
+	return &APITokenResponse{
+		ID:           token.ID,
+		CreationDate: token.CreationDate,
+		User:         token.User,
+		Name:         token.Name,
+		Prefix:       token.Prefix,
+		Scopes:       token.Scopes,
+		Tickers:      token.Tickers,
+		LastUsedAt:   token.LastUsedAt,
+	}
+}
+
+//
+func NewAPITokensResponse(tokens []APIToken) []*APITokenResponse {
+	tr := []*APITokenResponse{}
+	for _, token := range tokens {
+		tr = append(tr, NewAPITokenResponse(token))
+	}
+
+	return tr
+}
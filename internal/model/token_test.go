@@ -0,0 +1,40 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/systemli/ticker/internal/model"
+)
+
+func TestNewAPIToken(t *testing.T) {
+	apiToken, token := model.NewAPIToken(1, "bot")
+
+	assert.True(t, model.IsAPIToken(token))
+	assert.True(t, strings.HasPrefix(token, apiToken.Prefix))
+	assert.Equal(t, model.HashAPIToken(token), apiToken.Hash)
+	assert.NotContains(t, apiToken.Hash, token)
+	assert.False(t, apiToken.Restricted())
+}
+
+func TestAPIToken_Allows(t *testing.T) {
+	apiToken, _ := model.NewAPIToken(1, "bot")
+	assert.True(t, apiToken.Allows(1, model.PermissionWriteTicker))
+
+	apiToken.Scopes = []string{model.ScopeMessagesWrite}
+	assert.True(t, apiToken.Restricted())
+	assert.True(t, apiToken.Allows(1, model.PermissionPublishMessages))
+	assert.False(t, apiToken.Allows(1, model.PermissionWriteTicker))
+	assert.False(t, apiToken.Allows(1, model.PermissionApproveMessages))
+
+	apiToken.Tickers = []int{2}
+	assert.False(t, apiToken.Allows(1, model.PermissionPublishMessages))
+	assert.True(t, apiToken.Allows(2, model.PermissionPublishMessages))
+}
+
+func TestValidateScopes(t *testing.T) {
+	assert.Nil(t, model.ValidateScopes(nil))
+	assert.Nil(t, model.ValidateScopes(model.Scopes))
+	assert.Equal(t, "Scopes: must be one of tickers:read, tickers:write, messages:read, messages:write", model.ValidateScopes([]string{"admin"}).Error())
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/systemli/ticker/internal/totp"
+	"github.com/systemli/ticker/internal/util"
+)
+
+const (
+	//TOTPIssuer names the ticker in authenticator apps.
+	TOTPIssuer = "Ticker"
+	//RecoveryCodeCount is the number of recovery codes generated when two-factor authentication is enabled.
+	RecoveryCodeCount = 10
+)
+
+//EnrollTOTP creates a new encrypted secret for the User and returns it in plaintext.
+//Two-factor authentication is activated by EnableTOTP afterwards. It requires a configured secret.
+func (u *User) EnrollTOTP() (string, error) {
+	if !Config.TwoFactorAvailable() {
+		return "", errors.New(ErrorTOTPUnavailable)
+	}
+	if u.TOTPEnabled {
+		return "", errors.New(ErrorTOTPEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := util.Encrypt(Config.Secret, secret)
+	if err != nil {
+		return "", err
+	}
+
+	u.TOTPSecret = encrypted
+	u.TOTPStep = 0
+
+	return secret, nil
+}
+
+//TOTPURL returns the otpauth URL for the secret of the User.
+func (u *User) TOTPURL(secret string) string {
+	return totp.URL(TOTPIssuer, u.Email, secret)
+}
+
+//EnableTOTP activates two-factor authentication when the code matches the enrolled secret
+//and returns the new recovery codes in plaintext.
+func (u *User) EnableTOTP(code string) ([]string, error) {
+	if u.TOTPEnabled {
+		return nil, errors.New(ErrorTOTPEnabled)
+	}
+	if u.TOTPSecret == "" {
+		return nil, errors.New(ErrorTOTPNotEnrolled)
+	}
+	if !u.verifyTOTP(code) {
+		return nil, errors.New(ErrorInvalidOTP)
+	}
+
+	codes, err := u.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	u.TOTPEnabled = true
+
+	return codes, nil
+}
+
+//DisableTOTP removes the secret and the recovery codes of the User.
+func (u *User) DisableTOTP() {
+	u.TOTPEnabled = false
+	u.TOTPSecret = ""
+	u.TOTPStep = 0
+	u.RecoveryCodes = nil
+}
+
+//VerifySecondFactor returns true for a valid one-time password or an unused recovery code.
+//Both can only be used once, so the User has to be saved afterwards.
+func (u *User) VerifySecondFactor(code string) bool {
+	if !u.TOTPEnabled {
+		return false
+	}
+
+	if u.verifyTOTP(code) {
+		return true
+	}
+
+	return u.useRecoveryCode(code)
+}
+
+func (u *User) verifyTOTP(code string) bool {
+	secret, err := util.Decrypt(Config.Secret, u.TOTPSecret)
+	if err != nil {
+		return false
+	}
+
+	step, ok := totp.Verify(secret, code, time.Now())
+	if !ok || step <= u.TOTPStep {
+		return false
+	}
+
+	u.TOTPStep = step
+
+	return true
+}
+
+func (u *User) generateRecoveryCodes() ([]string, error) {
+	var codes, hashes []string
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		hash, err := hashPassword(code)
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code[0:4]+"-"+code[4:8]+"-"+code[8:12]+"-"+code[12:16])
+		hashes = append(hashes, hash)
+	}
+
+	u.RecoveryCodes = hashes
+
+	return codes, nil
+}
+
+func (u *User) useRecoveryCode(code string) bool {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	for i, hash := range u.RecoveryCodes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			u.RecoveryCodes = append(u.RecoveryCodes[:i], u.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
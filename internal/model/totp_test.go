@@ -0,0 +1,64 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/totp"
+)
+
+func TestUser_TOTP(t *testing.T) {
+	model.Config = model.NewConfig()
+
+	user, _ := model.NewUser("louis@systemli.org", "password")
+	assert.False(t, user.VerifySecondFactor("123456"))
+
+	_, err := user.EnableTOTP("123456")
+	assert.Equal(t, model.ErrorTOTPNotEnrolled, err.Error())
+
+	secret, err := user.EnrollTOTP()
+	assert.Nil(t, err)
+	assert.NotContains(t, user.TOTPSecret, secret)
+	assert.Contains(t, user.TOTPURL(secret), "secret="+secret)
+
+	_, err = user.EnableTOTP("000000")
+	assert.Equal(t, model.ErrorInvalidOTP, err.Error())
+
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(secret, step)
+	codes, err := user.EnableTOTP(code)
+	assert.Nil(t, err)
+	assert.True(t, user.TOTPEnabled)
+	assert.Len(t, codes, model.RecoveryCodeCount)
+	assert.Len(t, user.RecoveryCodes, model.RecoveryCodeCount)
+
+	_, err = user.EnrollTOTP()
+	assert.Equal(t, model.ErrorTOTPEnabled, err.Error())
+
+	// one-time passwords can't be reused
+	assert.False(t, user.VerifySecondFactor(code))
+	next, _ := totp.Code(secret, step+1)
+	assert.True(t, user.VerifySecondFactor(next))
+
+	assert.True(t, user.VerifySecondFactor(codes[0]))
+	assert.False(t, user.VerifySecondFactor(codes[0]))
+	assert.Len(t, user.RecoveryCodes, model.RecoveryCodeCount-1)
+
+	user.DisableTOTP()
+	assert.False(t, user.TOTPEnabled)
+	assert.Empty(t, user.TOTPSecret)
+	assert.Empty(t, user.RecoveryCodes)
+}
+
+func TestUser_TOTPWithoutSecret(t *testing.T) {
+	model.Config = model.NewConfig()
+	model.Config.SecretGenerated = true
+	defer func() { model.Config.SecretGenerated = false }()
+
+	user, _ := model.NewUser("louis@systemli.org", "password")
+	_, err := user.EnrollTOTP()
+	assert.Equal(t, model.ErrorTOTPUnavailable, err.Error())
+	assert.Empty(t, user.TOTPSecret)
+}
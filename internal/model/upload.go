@@ -0,0 +1,151 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//MaxAttachments is the maximum number of uploads attached to a single message.
+const MaxAttachments = 4
+
+//Upload represents an image uploaded for a ticker.
+type Upload struct {
+	ID           int       `storm:"id,increment"`
+	UUID         string    `storm:"unique"`
+	CreationDate time.Time `storm:"index"`
+	Ticker       int       `storm:"index"`
+	ContentType  string
+	Extension    string
+	Size         int
+	Width        int
+	Height       int
+}
+
+//Attachment references an Upload in a message.
+type Attachment struct {
+	UUID        string
+	Extension   string
+	ContentType string
+}
+
+type UploadResponse struct {
+	ID           int       `json:"id"`
+	UUID         string    `json:"uuid"`
+	CreationDate time.Time `json:"creation_date"`
+	ContentType  string    `json:"content_type"`
+	Size         int       `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+}
+
+type AttachmentResponse struct {
+	ContentType  string `json:"content_type"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+//NewUpload creates a new Upload with a random UUID.
+func NewUpload(ticker int, contentType, extension string) *Upload {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return &Upload{
+		UUID:         hex.EncodeToString(b),
+		CreationDate: time.Now(),
+		Ticker:       ticker,
+		ContentType:  contentType,
+		Extension:    extension,
+	}
+}
+
+//Attachment returns the reference to the Upload which is stored on messages.
+func (u *Upload) Attachment() Attachment {
+	return Attachment{
+		UUID:        u.UUID,
+		Extension:   u.Extension,
+		ContentType: u.ContentType,
+	}
+}
+
+//FileName returns the name of the file in the upload directory.
+func (a Attachment) FileName() string {
+	return a.UUID + "." + a.Extension
+}
+
+//ThumbnailName returns the name of the thumbnail in the upload directory.
+func (a Attachment) ThumbnailName() string {
+	ext := "png"
+	if a.ContentType == "image/jpeg" {
+		ext = "jpg"
+	}
+
+	return a.UUID + "_thumb." + ext
+}
+
+//FilePath returns the path of the file.
+func (a Attachment) FilePath() string {
+	return filepath.Join(Config.UploadPath, a.FileName())
+}
+
+//ThumbnailPath returns the path of the thumbnail.
+func (a Attachment) ThumbnailPath() string {
+	return filepath.Join(Config.UploadPath, a.ThumbnailName())
+}
+
+//URL returns the public URL of the file.
+func (a Attachment) URL() string {
+	return mediaURL(a.FileName())
+}
+
+//ThumbnailURL returns the public URL of the thumbnail.
+func (a Attachment) ThumbnailURL() string {
+	return mediaURL(a.ThumbnailName())
+}
+
+//
+func NewUploadResponse(upload Upload) *UploadResponse {
+	return &UploadResponse{
+		ID:           upload.ID,
+		UUID:         upload.UUID,
+		CreationDate: upload.CreationDate,
+		ContentType:  upload.ContentType,
+		Size:         upload.Size,
+		Width:        upload.Width,
+		Height:       upload.Height,
+		URL:          upload.Attachment().URL(),
+		ThumbnailURL: upload.Attachment().ThumbnailURL(),
+	}
+}
+
+//
+func NewUploadsResponse(uploads []Upload) []*UploadResponse {
+	ur := []*UploadResponse{}
+	for _, upload := range uploads {
+		ur = append(ur, NewUploadResponse(upload))
+	}
+
+	return ur
+}
+
+//
+func NewAttachmentsResponse(attachments []Attachment) []*AttachmentResponse {
+	ar := []*AttachmentResponse{}
+	for _, attachment := range attachments {
+		ar = append(ar, &AttachmentResponse{
+			ContentType:  attachment.ContentType,
+			URL:          attachment.URL(),
+			ThumbnailURL: attachment.ThumbnailURL(),
+		})
+	}
+
+	return ar
+}
+
+func mediaURL(name string) string {
+	return strings.TrimSuffix(Config.UploadURL, "/") + "/v1/media/" + name
+}
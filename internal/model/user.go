@@ -9,23 +9,36 @@ import (
 
 //
 type User struct {
-	ID                int       `storm:"id,increment"`
-	CreationDate      time.Time `storm:"index"`
-	Email             string    `storm:"unique"`
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	Email        string    `storm:"unique"`
+	//Role is the role on all tickers of the user without an entry in TickerRoles.
 	Role              string
 	EncryptedPassword string
 	IsSuperAdmin      bool
 	Tickers           []int
+	TickerRoles       map[int]string
+	TelegramID        int64 `storm:"index"`
+	//TOTPSecret is the encrypted secret for the one-time passwords of the two-factor authentication.
+	TOTPSecret  string
+	TOTPEnabled bool
+	//TOTPStep is the time step of the last accepted one-time password, which can't be used again.
+	TOTPStep int64
+	//RecoveryCodes are the hashes of the unused recovery codes.
+	RecoveryCodes []string
 }
 
 //
 type UserResponse struct {
-	ID           int       `json:"id"`
-	CreationDate time.Time `json:"creation_date"`
-	Email        string    `json:"email"`
-	Role         string    `json:"role"`
-	IsSuperAdmin bool      `json:"is_super_admin"`
-	Tickers      []int     `json:"tickers"`
+	ID           int            `json:"id"`
+	CreationDate time.Time      `json:"creation_date"`
+	Email        string         `json:"email"`
+	Role         string         `json:"role"`
+	IsSuperAdmin bool           `json:"is_super_admin"`
+	Tickers      []int          `json:"tickers"`
+	TickerRoles  map[int]string `json:"ticker_roles"`
+	TelegramID   int64          `json:"telegram_id"`
+	TOTPEnabled  bool           `json:"totp_enabled"`
 }
 
 //NewUser returns a new User.
@@ -61,6 +74,9 @@ func NewUserResponse(user User) *UserResponse {
 		Role:         user.Role,
 		IsSuperAdmin: user.IsSuperAdmin,
 		Tickers:      user.Tickers,
+		TickerRoles:  user.TickerRoles,
+		TelegramID:   user.TelegramID,
+		TOTPEnabled:  user.TOTPEnabled,
 	}
 }
 
@@ -95,9 +111,58 @@ func (u *User) AddTicker(ticker Ticker) {
 	u.Tickers = util.Append(u.Tickers, ticker.ID)
 }
 
+//HasTicker returns true when the User has access to the Ticker.
+func (u *User) HasTicker(ticker Ticker) bool {
+	return u.IsSuperAdmin || util.Contains(u.Tickers, ticker.ID)
+}
+
 //RemoveTicker removes a Ticker from User.
 func (u *User) RemoveTicker(ticker Ticker) {
 	u.Tickers = util.Remove(u.Tickers, ticker.ID)
+	delete(u.TickerRoles, ticker.ID)
+}
+
+//SetTickerRole sets the role of the User on the Ticker.
+func (u *User) SetTickerRole(ticker Ticker, role string) {
+	if u.TickerRoles == nil {
+		u.TickerRoles = make(map[int]string)
+	}
+	u.TickerRoles[ticker.ID] = role
+}
+
+//TickerRole returns the effective role of the User on the ticker, or an empty string when the User is no member.
+func (u *User) TickerRole(tickerID int) string {
+	if u.IsSuperAdmin {
+		return RoleOwner
+	}
+	if !util.Contains(u.Tickers, tickerID) {
+		return ""
+	}
+	if role, ok := u.TickerRoles[tickerID]; ok && validRole(role) {
+		return role
+	}
+	// roles stored before the per-ticker roles, like "user", fall back to the default
+	if validRole(u.Role) {
+		return u.Role
+	}
+
+	return DefaultRole
+}
+
+//Permissions returns the permissions of the User on the ticker.
+func (u *User) Permissions(tickerID int) []string {
+	return RolePermissions[u.TickerRole(tickerID)]
+}
+
+//Can returns true when the User has the permission on the ticker.
+func (u *User) Can(tickerID int, permission string) bool {
+	for _, p := range u.Permissions(tickerID) {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
 }
 
 // hashPassword generates a hashed password from a plaintext string
@@ -25,3 +25,46 @@ func TestUser_Authenticate(t *testing.T) {
 
 	assert.True(t, user.Authenticate("password"))
 }
+
+func TestUser_TickerRole(t *testing.T) {
+	user, err := model.NewUser("louis@systemli.org", "password")
+	if err != nil {
+		t.Fail()
+	}
+
+	user.AddTicker(model.Ticker{ID: 1})
+	user.AddTicker(model.Ticker{ID: 2})
+	user.SetTickerRole(model.Ticker{ID: 2}, model.RoleViewer)
+
+	assert.Equal(t, model.DefaultRole, user.TickerRole(1))
+	assert.Equal(t, model.RoleViewer, user.TickerRole(2))
+	assert.Equal(t, "", user.TickerRole(3))
+
+	// legacy roles without permissions
+	user.Role = "user"
+	assert.Equal(t, model.DefaultRole, user.TickerRole(1))
+	assert.True(t, user.Can(1, model.PermissionReadTicker))
+
+	user.Role = model.RoleContributor
+	assert.Equal(t, model.RoleContributor, user.TickerRole(1))
+	assert.True(t, user.Can(1, model.PermissionCreateMessages))
+	assert.False(t, user.Can(1, model.PermissionPublishMessages))
+	assert.False(t, user.Can(2, model.PermissionCreateMessages))
+	assert.False(t, user.Can(3, model.PermissionReadTicker))
+
+	user.RemoveTicker(model.Ticker{ID: 2})
+	assert.Equal(t, "", user.TickerRole(2))
+	assert.Empty(t, user.TickerRoles)
+
+	user.IsSuperAdmin = true
+	assert.Equal(t, model.RoleOwner, user.TickerRole(3))
+	assert.True(t, user.Can(3, model.PermissionWriteTicker))
+}
+
+func TestValidateRole(t *testing.T) {
+	for _, role := range model.Roles {
+		assert.Nil(t, model.ValidateRole(role))
+	}
+
+	assert.Equal(t, "Role: must be one of owner, editor, contributor, viewer", model.ValidateRole("admin").Error())
+}
@@ -0,0 +1,144 @@
+package model
+
+import "time"
+
+const (
+	EventMessageCreated = `message.created`
+	EventMessageDeleted = `message.deleted`
+	EventTickerUpdated  = `ticker.updated`
+	EventTickerReset    = `ticker.reset`
+)
+
+//WebhookEvents contains all events a webhook can subscribe to.
+var WebhookEvents = []string{EventMessageCreated, EventMessageDeleted, EventTickerUpdated, EventTickerReset}
+
+//Webhook represents a subscription for ticker events.
+type Webhook struct {
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	Ticker       int       `storm:"index"`
+	URL          string
+	Secret       string
+	Events       []string
+}
+
+//WebhookDelivery represents a single delivery attempt for a webhook.
+type WebhookDelivery struct {
+	ID           int       `storm:"id,increment"`
+	CreationDate time.Time `storm:"index"`
+	Webhook      int       `storm:"index"`
+	Event        string
+	Attempt      int
+	StatusCode   int
+	Error        string
+}
+
+//PendingWebhookDelivery is an event which isn't delivered to the webhook yet. It's stored until the delivery
+//succeeds or all attempts failed, so retries survive restarts.
+type PendingWebhookDelivery struct {
+	ID           int `storm:"id,increment"`
+	CreationDate time.Time
+	Webhook      int `storm:"index"`
+	Event        string
+	Body         []byte
+	Attempts     int
+	NextAttempt  time.Time `storm:"index"`
+}
+
+type WebhookResponse struct {
+	ID           int       `json:"id"`
+	CreationDate time.Time `json:"creation_date"`
+	Ticker       int       `json:"ticker"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"secret,omitempty"`
+	Events       []string  `json:"events"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID           int       `json:"id"`
+	CreationDate time.Time `json:"creation_date"`
+	Webhook      int       `json:"webhook"`
+	Event        string    `json:"event"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   int       `json:"status_code"`
+	Error        string    `json:"error"`
+}
+
+//NewWebhook creates new Webhook
+func NewWebhook() *Webhook {
+	return &Webhook{
+		CreationDate: time.Now(),
+		Events:       []string{},
+	}
+}
+
+//NewWebhookDelivery creates new WebhookDelivery
+func NewWebhookDelivery(webhook Webhook, event string, attempt int) *WebhookDelivery {
+	return &WebhookDelivery{
+		CreationDate: time.Now(),
+		Webhook:      webhook.ID,
+		Event:        event,
+		Attempt:      attempt,
+	}
+}
+
+//Subscribed returns true when the webhook listens to the event.
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+//NewWebhookResponse returns the webhook without its secret, which is only shown once after the creation.
+func NewWebhookResponse(webhook Webhook) *WebhookResponse {
+	return &WebhookResponse{
+		ID:           webhook.ID,
+		CreationDate: webhook.CreationDate,
+		Ticker:       webhook.Ticker,
+		URL:          webhook.URL,
+		Events:       webhook.Events,
+	}
+}
+
+//
+func NewWebhooksResponse(webhooks []Webhook) []*WebhookResponse {
+	var wr []*WebhookResponse
+	for _, webhook := range webhooks {
+		wr = append(wr, NewWebhookResponse(webhook))
+	}
+
+	return wr
+}
+
+//
+func NewWebhookDeliveriesResponse(deliveries []WebhookDelivery) []*WebhookDeliveryResponse {
+	var dr []*WebhookDeliveryResponse
+	for _, delivery := range deliveries {
+		dr = append(dr, &WebhookDeliveryResponse{
+			ID:           delivery.ID,
+			CreationDate: delivery.CreationDate,
+			Webhook:      delivery.Webhook,
+			Event:        delivery.Event,
+			Attempt:      delivery.Attempt,
+			StatusCode:   delivery.StatusCode,
+			Error:        delivery.Error,
+		})
+	}
+
+	return dr
+}
+
+//ValidWebhookEvent returns true when the event is known.
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
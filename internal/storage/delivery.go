@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+//FindDeliveriesByMessages returns the bridge deliveries for the given messages.
+func FindDeliveriesByMessages(ids []int) ([]BridgeDelivery, error) {
+	var deliveries []BridgeDelivery
+	err := DB.Select(q.In("Message", ids)).OrderBy("Bridge").Find(&deliveries)
+	if err == storm.ErrNotFound {
+		return deliveries, nil
+	}
+
+	return deliveries, err
+}
+
+//FindDueDeliveries returns all pending bridge deliveries which should be retried now.
+func FindDueDeliveries() ([]BridgeDelivery, error) {
+	var deliveries []BridgeDelivery
+	err := DB.Select(q.Eq("State", DeliveryStatePending), q.Lte("NextAttempt", time.Now())).Find(&deliveries)
+	if err == storm.ErrNotFound {
+		return deliveries, nil
+	}
+
+	return deliveries, err
+}
+
+//DeleteDeliveries removes all bridge deliveries matching the field value.
+func DeleteDeliveries(field string, value int) error {
+	err := DB.Select(q.Eq(field, value)).Delete(new(BridgeDelivery))
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"time"
+
+	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
 
 	. "github.com/systemli/ticker/internal/model"
@@ -15,12 +18,12 @@ func FindByTicker(ticker *Ticker, pagination *Pagination) ([]Message, error) {
 		return messages, nil
 	}
 
-	matcher := q.Eq("Ticker", ticker.ID)
+	matcher := q.And(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false))
 	if pagination.GetBefore() != 0 {
-		matcher = q.And(q.Eq("Ticker", ticker.ID), q.Lt("ID", pagination.GetBefore()))
+		matcher = q.And(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false), q.Lt("ID", pagination.GetBefore()))
 	}
 	if pagination.GetAfter() != 0 {
-		matcher = q.And(q.Eq("Ticker", ticker.ID), q.Gt("ID", pagination.GetAfter()))
+		matcher = q.And(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false), q.Gt("ID", pagination.GetAfter()))
 	}
 
 	err := DB.Select(matcher).OrderBy("CreationDate").Limit(pagination.GetLimit()).Reverse().Find(&messages)
@@ -32,3 +35,98 @@ func FindByTicker(ticker *Ticker, pagination *Pagination) ([]Message, error) {
 	}
 	return messages, nil
 }
+
+//FindByTickerAfter returns the published messages of the ticker with an ID greater than the given one, oldest first.
+func FindByTickerAfter(ticker *Ticker, id int) ([]Message, error) {
+	var messages []Message
+
+	if !ticker.Active {
+		return messages, nil
+	}
+
+	err := DB.Select(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false), q.Gt("ID", id)).OrderBy("ID").Find(&messages)
+	if err != nil {
+		if err.Error() == "not found" {
+			return messages, nil
+		}
+		return messages, err
+	}
+	return messages, nil
+}
+
+//FindPinnedByTicker returns the pinned messages of the ticker, newest first.
+func FindPinnedByTicker(ticker *Ticker) ([]Message, error) {
+	var messages []Message
+
+	if !ticker.Active {
+		return messages, nil
+	}
+
+	err := DB.Select(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false), q.Eq("Pinned", true)).OrderBy("CreationDate").Reverse().Find(&messages)
+	if err != nil {
+		if err.Error() == "not found" {
+			return messages, nil
+		}
+		return messages, err
+	}
+	return messages, nil
+}
+
+//FindGeoMessagesByTicker returns all messages of the ticker with a geometry.
+func FindGeoMessagesByTicker(ticker *Ticker) ([]Message, error) {
+	var messages []Message
+
+	if !ticker.Active {
+		return messages, nil
+	}
+
+	err := DB.Select(q.Eq("Ticker", ticker.ID), q.Eq("Scheduled", false), q.Eq("Draft", false), q.Not(q.Eq("Geometry", nil))).OrderBy("CreationDate").Reverse().Find(&messages)
+	if err != nil {
+		if err.Error() == "not found" {
+			return messages, nil
+		}
+		return messages, err
+	}
+	return messages, nil
+}
+
+//FindDueScheduledMessages returns all scheduled messages which should be published now.
+func FindDueScheduledMessages() ([]Message, error) {
+	var messages []Message
+	err := DB.Select(q.Eq("Scheduled", true), q.Lte("PublishAt", time.Now())).OrderBy("PublishAt").Find(&messages)
+	if err == storm.ErrNotFound {
+		return messages, nil
+	}
+
+	return messages, err
+}
+
+//UpdateMessage saves the changes of a stored message and the revision of the previous text, if any. It fails with
+//storm.ErrNotFound when the message was removed in the meantime, e.g. because it was published under a new ID.
+func UpdateMessage(message *Message, revision *MessageRevision) error {
+	tx, err := DB.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stored Message
+	err = tx.One("ID", message.ID, &stored)
+	if err != nil {
+		return err
+	}
+
+	if revision != nil {
+		err = tx.Save(revision)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Save(message)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/asdine/storm"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
@@ -132,6 +133,37 @@ func TestFindByTickerInactive(t *testing.T) {
 	assert.Equal(t, len(messages), 0)
 }
 
+func TestUpdateMessage(t *testing.T) {
+	setup()
+
+	message := model.NewMessage()
+	message.Ticker = 1
+	message.Text = "First Message"
+	storage.DB.Save(message)
+
+	revision := model.NewMessageRevision(*message)
+	message.Text = "Edited Message"
+
+	err := storage.UpdateMessage(message, revision)
+	assert.Nil(t, err)
+
+	revisions, _ := storage.FindRevisionsByMessage(message.ID)
+	assert.Len(t, revisions, 1)
+
+	// a removed message isn't recreated
+	storage.DB.DeleteStruct(message)
+
+	err = storage.UpdateMessage(message, model.NewMessageRevision(*message))
+	assert.Equal(t, storm.ErrNotFound, err)
+
+	var messages []model.Message
+	storage.DB.All(&messages)
+	assert.Empty(t, messages)
+
+	revisions, _ = storage.FindRevisionsByMessage(message.ID)
+	assert.Len(t, revisions, 1)
+}
+
 func createContext(query string) gin.Context {
 	req := http.Request{
 		URL: &url.URL{
@@ -149,4 +181,5 @@ func setup() {
 	storage.DB.Drop("Ticker")
 	storage.DB.Drop("Message")
 	storage.DB.Drop("User")
+	storage.DB.Drop("MessageRevision")
 }
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+//FindRevisionsByMessage returns the previous versions of the message, oldest first.
+func FindRevisionsByMessage(messageID int) ([]MessageRevision, error) {
+	var revisions []MessageRevision
+	err := DB.Select(q.Eq("Message", messageID)).OrderBy("ID").Find(&revisions)
+	if err == storm.ErrNotFound {
+		return revisions, nil
+	}
+
+	return revisions, err
+}
+
+//DeleteRevisions removes all message revisions matching the field value.
+func DeleteRevisions(field string, value int) error {
+	err := DB.Select(q.Eq(field, value)).Delete(new(MessageRevision))
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
@@ -50,3 +50,20 @@ func GetRefreshIntervalValue() int {
 
 	return value
 }
+
+//GetEnforceTwoFactor returns whether two-factor authentication is enforced for all users
+func GetEnforceTwoFactor() *Setting {
+	setting, err := FindSetting(SettingEnforceTwoFactor)
+	if err != nil {
+		return NewSetting(SettingEnforceTwoFactor, false)
+	}
+
+	return setting
+}
+
+//GetEnforceTwoFactorValue returns concrete boolean value
+func GetEnforceTwoFactorValue() bool {
+	value, _ := GetEnforceTwoFactor().Value.(bool)
+
+	return value
+}
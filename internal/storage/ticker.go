@@ -1,17 +1,42 @@
 package storage
 
 import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
 	. "github.com/systemli/ticker/internal/model"
 )
 
-//Find Ticker Configuration by domain
+//Find Ticker Configuration by domain, which is either the primary domain or one of the aliases
 func FindTicker(domain string) (*Ticker, error) {
 	var ticker Ticker
+	domain = NormalizeDomain(domain)
 
 	err := DB.One("Domain", domain, &ticker)
+	if err == storm.ErrNotFound {
+		err = DB.Select(q.NewFieldMatcher("Aliases", aliasMatcher(domain))).First(&ticker)
+	}
 	if err != nil {
 		return &ticker, err
 	}
 
 	return &ticker, nil
 }
+
+//aliasMatcher matches tickers which have the domain in their aliases.
+type aliasMatcher string
+
+func (m aliasMatcher) MatchField(v interface{}) (bool, error) {
+	aliases, ok := v.([]string)
+	if !ok {
+		return false, nil
+	}
+
+	for _, alias := range aliases {
+		if alias == string(m) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,32 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+)
+
+func TestFindTicker(t *testing.T) {
+	setup()
+
+	ticker := &model.Ticker{
+		ID:      1,
+		Domain:  "demoticker.org",
+		Aliases: []string{"demoticker.onion", "prozessticker.org"},
+	}
+
+	storage.DB.Save(ticker)
+
+	for _, domain := range []string{"demoticker.org", "www.Demoticker.org", "demoticker.onion", "prozessticker.org:8080"} {
+		found, err := storage.FindTicker(domain)
+		assert.Nil(t, err, domain)
+		assert.Equal(t, 1, found.ID, domain)
+	}
+
+	found, err := storage.FindTicker("unknown.org")
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, found.ID)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+//apiTokenTouchInterval limits how often the last usage of an API token is written.
+const apiTokenTouchInterval = time.Minute
+
+//FindAPIToken returns the API token for the plaintext token.
+func FindAPIToken(token string) (*APIToken, error) {
+	var apiToken APIToken
+	err := DB.One("Hash", HashAPIToken(token), &apiToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiToken, nil
+}
+
+//FindAPITokensByUser returns all API tokens of the user.
+func FindAPITokensByUser(userID int) ([]APIToken, error) {
+	var tokens []APIToken
+	err := DB.Find("User", userID, &tokens)
+	if err == storm.ErrNotFound {
+		return tokens, nil
+	}
+
+	return tokens, err
+}
+
+//TouchAPIToken records the usage of the API token.
+func TouchAPIToken(token *APIToken) error {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < apiTokenTouchInterval {
+		return nil
+	}
+
+	token.LastUsedAt = &now
+
+	return DB.UpdateField(token, "LastUsedAt", &now)
+}
+
+//DeleteAPITokensByUser removes all API tokens of the user.
+func DeleteAPITokensByUser(userID int) error {
+	err := DB.Select(q.Eq("User", userID)).Delete(new(APIToken))
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
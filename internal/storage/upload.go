@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"os"
+
+	"github.com/asdine/storm"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+//FindUploadByUUID returns the upload for the given UUID.
+func FindUploadByUUID(uuid string) (*Upload, error) {
+	var upload Upload
+	err := DB.One("UUID", uuid, &upload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+//FindUploadsByTicker returns all uploads for the ticker.
+func FindUploadsByTicker(tickerID int) ([]Upload, error) {
+	var uploads []Upload
+	err := DB.Find("Ticker", tickerID, &uploads)
+	if err == storm.ErrNotFound {
+		return uploads, nil
+	}
+
+	return uploads, err
+}
+
+//DeleteUpload removes the upload and its files.
+func DeleteUpload(upload Upload) error {
+	attachment := upload.Attachment()
+	for _, path := range []string{attachment.FilePath(), attachment.ThumbnailPath()} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return DB.DeleteStruct(&upload)
+}
+
+//DeleteUploadsByTicker removes all uploads of the ticker and their files.
+func DeleteUploadsByTicker(tickerID int) error {
+	uploads, err := FindUploadsByTicker(tickerID)
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		err = DeleteUpload(upload)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
 	. "github.com/systemli/ticker/internal/model"
 )
@@ -15,6 +16,13 @@ func FindUserByID(id int) (*User, error) {
 	return &user, err
 }
 
+//FindUserByTelegramID returns user if one is connected with the given telegram user id.
+func FindUserByTelegramID(id int64) (*User, error) {
+	var user User
+	err := DB.One("TelegramID", id, &user)
+	return &user, err
+}
+
 //FindUsers returns all users.
 func FindUsers() ([]User, error) {
 	var users []User
@@ -67,8 +75,8 @@ func UserAuthenticate(email, password string) (*User, error) {
 	return &user, errors.New("authentication failed")
 }
 
-//AddUsersToTicker append Ticker to the given slice of users.
-func AddUsersToTicker(ticker Ticker, ids []int) error {
+//AddUsersToTicker append Ticker to the given slice of users. A non empty role is set for all of them.
+func AddUsersToTicker(ticker Ticker, ids []int, role string) error {
 	var users []User
 
 	err := DB.Select(q.In("ID", ids)).Find(&users)
@@ -81,6 +89,9 @@ func AddUsersToTicker(ticker Ticker, ids []int) error {
 			continue
 		}
 		user.AddTicker(ticker)
+		if role != "" {
+			user.SetTickerRole(ticker, role)
+		}
 		err = DB.Save(&user)
 	}
 
@@ -93,3 +104,40 @@ func RemoveTickerFromUser(ticker Ticker, user User) error {
 
 	return DB.Save(&user)
 }
+
+//MigrateTickerRoles makes the members of the tickers owners of them, as they could manage their tickers before the
+//roles were introduced. It runs once, users added later keep their roles.
+func MigrateTickerRoles() (int, error) {
+	var setting Setting
+	err := DB.One("Name", SettingTickerRolesMigrated, &setting)
+	if err == nil {
+		return 0, nil
+	}
+	if err != storm.ErrNotFound {
+		return 0, err
+	}
+
+	var users []User
+	err = DB.All(&users)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, user := range users {
+		if user.IsSuperAdmin || len(user.TickerRoles) > 0 || len(user.Tickers) == 0 {
+			continue
+		}
+
+		for _, tickerID := range user.Tickers {
+			user.SetTickerRole(Ticker{ID: tickerID}, RoleOwner)
+		}
+		err = DB.Save(&user)
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, DB.Save(NewSetting(SettingTickerRolesMigrated, true))
+}
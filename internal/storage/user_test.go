@@ -52,3 +52,40 @@ func TestUserAuthenticate(t *testing.T) {
 	assert.Equal(t, 0, user.ID)
 	assert.NotNil(t, err)
 }
+
+func TestMigrateTickerRoles(t *testing.T) {
+	setup()
+	DB.Drop("Setting")
+
+	// a user stored before the roles were introduced
+	legacy := User{Email: "legacy@systemli.org", Role: "user", Tickers: []int{1, 2}}
+	DB.Save(&legacy)
+
+	editor, _ := NewUser("editor@systemli.org", "password")
+	editor.AddTicker(Ticker{ID: 1})
+	editor.SetTickerRole(Ticker{ID: 1}, RoleEditor)
+	DB.Save(editor)
+
+	migrated, err := MigrateTickerRoles()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, migrated)
+
+	user, _ := FindUserByID(legacy.ID)
+	assert.Equal(t, RoleOwner, user.TickerRole(1))
+	assert.Equal(t, RoleOwner, user.TickerRole(2))
+
+	user, _ = FindUserByID(editor.ID)
+	assert.Equal(t, RoleEditor, user.TickerRole(1))
+
+	// members added later get the default role
+	member, _ := NewUser("member@systemli.org", "password")
+	member.AddTicker(Ticker{ID: 1})
+	DB.Save(member)
+
+	migrated, err = MigrateTickerRoles()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, migrated)
+
+	user, _ = FindUserByID(member.ID)
+	assert.Equal(t, DefaultRole, user.TickerRole(1))
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	. "github.com/systemli/ticker/internal/model"
+)
+
+//FindWebhooksByTicker returns all webhooks for the ticker.
+func FindWebhooksByTicker(tickerID int) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := DB.Find("Ticker", tickerID, &webhooks)
+	if err == storm.ErrNotFound {
+		return webhooks, nil
+	}
+
+	return webhooks, err
+}
+
+//FindWebhookDeliveries returns the latest deliveries for the webhook.
+func FindWebhookDeliveries(webhookID, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := DB.Select(q.Eq("Webhook", webhookID)).OrderBy("ID").Reverse().Limit(limit).Find(&deliveries)
+	if err == storm.ErrNotFound {
+		return deliveries, nil
+	}
+
+	return deliveries, err
+}
+
+//PruneWebhookDeliveries removes all but the latest deliveries of the webhook.
+func PruneWebhookDeliveries(webhookID, keep int) error {
+	err := DB.Select(q.Eq("Webhook", webhookID)).OrderBy("ID").Reverse().Skip(keep).Delete(new(WebhookDelivery))
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
+
+//FindDuePendingWebhookDeliveries returns all pending webhook deliveries which should be attempted now.
+func FindDuePendingWebhookDeliveries() ([]PendingWebhookDelivery, error) {
+	var deliveries []PendingWebhookDelivery
+	err := DB.Select(q.Lte("NextAttempt", time.Now())).Find(&deliveries)
+	if err == storm.ErrNotFound {
+		return deliveries, nil
+	}
+
+	return deliveries, err
+}
+
+//DeleteWebhook removes the webhook, its pending deliveries and its delivery log.
+func DeleteWebhook(webhook Webhook) error {
+	err := DB.Select(q.Eq("Webhook", webhook.ID)).Delete(new(WebhookDelivery))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	err = DB.Select(q.Eq("Webhook", webhook.ID)).Delete(new(PendingWebhookDelivery))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	return DB.DeleteStruct(&webhook)
+}
@@ -0,0 +1,58 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//API calls the methods of the Telegram Bot API.
+type API struct {
+	Token    string
+	Endpoint string
+	Client   *http.Client
+}
+
+//Call sends the form encoded params to the method and decodes the result into v, unless v is nil.
+func (a *API) Call(method string, params url.Values, v interface{}) error {
+	return a.Post(method, strings.NewReader(params.Encode()), "application/x-www-form-urlencoded", v)
+}
+
+//Post sends the body to the method and decodes the result into v, unless v is nil.
+func (a *API) Post(method string, body io.Reader, contentType string, v interface{}) error {
+	u := strings.TrimSuffix(a.Endpoint, "/") + "/bot" + a.Token + "/" + method
+	res, err := a.Client.Post(u, contentType, body)
+	if err != nil {
+		// don't leak the bot token which is part of the url
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return errors.Wrap(err, "telegram: "+method)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		return errors.Wrap(err, "telegram: "+method)
+	}
+
+	if !response.OK {
+		return errors.Errorf("telegram: %s returned %d %s", method, res.StatusCode, response.Description)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Result, v)
+}
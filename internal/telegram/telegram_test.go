@@ -0,0 +1,45 @@
+package telegram_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/telegram"
+)
+
+func TestCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/getChat" || r.FormValue("chat_id") != "@channel" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"id":-100}}`))
+	}))
+	defer server.Close()
+
+	api := &telegram.API{Token: "token", Endpoint: server.URL + "/", Client: server.Client()}
+
+	var chat struct {
+		ID int64 `json:"id"`
+	}
+	err := api.Call("getChat", url.Values{"chat_id": {"@channel"}}, &chat)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-100), chat.ID)
+
+	err = api.Call("getChat", url.Values{"chat_id": {"@unknown"}}, nil)
+	assert.EqualError(t, err, "telegram: getChat returned 400 Bad Request: chat not found")
+}
+
+func TestCallHidesToken(t *testing.T) {
+	api := &telegram.API{Token: "secret-token", Endpoint: "http://127.0.0.1:0", Client: http.DefaultClient}
+
+	err := api.Call("getMe", url.Values{}, nil)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
@@ -0,0 +1,73 @@
+package totp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/systemli/ticker/internal/totp"
+)
+
+// base32 of the ascii secret "12345678901234567890" from RFC 6238
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for ts, expected := range vectors {
+		code, err := totp.Code(secret, totp.Step(time.Unix(ts, 0)))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code)
+	}
+
+	_, err := totp.Code("not base32!", 1)
+	assert.NotNil(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := totp.Verify(secret, "081804", now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	// codes of the previous period are accepted
+	_, ok = totp.Verify(secret, "081804", now.Add(totp.Period*time.Second))
+	assert.True(t, ok)
+
+	_, ok = totp.Verify(secret, "081804", now.Add(3*totp.Period*time.Second))
+	assert.False(t, ok)
+
+	_, ok = totp.Verify(secret, "000000", now)
+	assert.False(t, ok)
+
+	_, ok = totp.Verify(secret, "81804", now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	s, err := totp.GenerateSecret()
+	assert.Nil(t, err)
+	assert.Len(t, s, 32)
+
+	code, err := totp.Code(s, totp.Step(time.Now()))
+	assert.Nil(t, err)
+
+	_, ok := totp.Verify(s, code, time.Now())
+	assert.True(t, ok)
+}
+
+func TestURL(t *testing.T) {
+	u := totp.URL("ticker", "louis@systemli.org", secret)
+
+	assert.True(t, strings.HasPrefix(u, "otpauth://totp/ticker:louis@systemli.org?"))
+	assert.Contains(t, u, "secret="+secret)
+	assert.Contains(t, u, "issuer=ticker")
+}
@@ -0,0 +1,25 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/systemli/ticker/internal/util"
+)
+
+func TestEncrypt(t *testing.T) {
+	ciphertext, err := Encrypt("secret", "plaintext")
+	assert.Nil(t, err)
+	assert.NotContains(t, ciphertext, "plaintext")
+
+	plaintext, err := Decrypt("secret", ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, "plaintext", plaintext)
+
+	_, err = Decrypt("other", ciphertext)
+	assert.NotNil(t, err)
+
+	_, err = Decrypt("secret", "c2hvcnQ=")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	//MaxTweetLength is the maximum weighted length of a single tweet.
+	MaxTweetLength = 280
+	//TweetURLLength is the length Twitter counts for every URL regardless of its actual length.
+	TweetURLLength = 23
+
+	weightScale   = 100
+	defaultWeight = 200
+)
+
+var (
+	urlPattern = regexp.MustCompile(`https?://\S+`)
+
+	//Code point ranges which count as a single character, everything else (e.g. CJK and emoji) counts twice.
+	lightRanges = [][2]rune{
+		{0x0000, 0x10FF},
+		{0x2000, 0x200D},
+		{0x2010, 0x201F},
+		{0x2032, 0x2037},
+	}
+)
+
+//TweetLength returns the weighted length of the text as counted by Twitter.
+//Emoji sequences are counted per code point, so the length might be slightly overestimated.
+func TweetLength(text string) int {
+	weight := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		weight += textWeight(text[last:loc[0]]) + TweetURLLength*weightScale
+		last = loc[1]
+	}
+	weight += textWeight(text[last:])
+
+	return (weight + weightScale - 1) / weightScale
+}
+
+//SplitTweet splits the text into a numbered thread when it exceeds MaxTweetLength.
+//The text is split between words, URLs are never broken up.
+func SplitTweet(text string) []string {
+	text = strings.TrimSpace(text)
+	if TweetLength(text) <= MaxTweetLength {
+		return []string{text}
+	}
+
+	total := 2
+	for {
+		counter := TweetLength(fmt.Sprintf(" %d/%d", total, total))
+		parts := splitWords(text, MaxTweetLength-counter)
+		if len(parts) <= total {
+			for i := range parts {
+				parts[i] = fmt.Sprintf("%s %d/%d", parts[i], i+1, len(parts))
+			}
+
+			return parts
+		}
+
+		total = len(parts)
+	}
+}
+
+func splitWords(text string, limit int) []string {
+	var parts []string
+	var current string
+
+	flush := func() {
+		current = strings.TrimSpace(current)
+		if current != "" {
+			parts = append(parts, current)
+		}
+		current = ""
+	}
+
+	for _, word := range strings.Split(text, " ") {
+		if current == "" {
+			current = word
+		} else if TweetLength(current+" "+word) <= limit {
+			current = current + " " + word
+			continue
+		} else {
+			flush()
+			current = word
+		}
+
+		// a single word which doesn't fit into a tweet has to be broken up
+		for TweetLength(current) > limit && !urlPattern.MatchString(current) {
+			head, tail := splitRunes(current, limit)
+			current = head
+			flush()
+			current = tail
+		}
+	}
+	flush()
+
+	return parts
+}
+
+func splitRunes(word string, limit int) (string, string) {
+	weight := 0
+	for i, r := range word {
+		weight += runeWeight(r)
+		if weight > limit*weightScale {
+			return word[:i], word[i:]
+		}
+	}
+
+	return word, ""
+}
+
+func textWeight(text string) int {
+	weight := 0
+	for _, r := range text {
+		weight += runeWeight(r)
+	}
+
+	return weight
+}
+
+func runeWeight(r rune) int {
+	for _, lr := range lightRanges {
+		if r >= lr[0] && r <= lr[1] {
+			return weightScale
+		}
+	}
+
+	return defaultWeight
+}
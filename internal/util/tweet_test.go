@@ -0,0 +1,57 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/systemli/ticker/internal/util"
+)
+
+func TestTweetLength(t *testing.T) {
+	assert.Equal(t, 7, TweetLength("example"))
+	assert.Equal(t, 5, TweetLength("crème"))
+	assert.Equal(t, 4, TweetLength("日本"))
+	assert.Equal(t, 2, TweetLength("🔥"))
+	assert.Equal(t, 5+TweetURLLength, TweetLength("read https://www.systemli.org/en/ticker/with/a/very/long/path"))
+}
+
+func TestSplitTweet(t *testing.T) {
+	assert.Equal(t, []string{"example"}, SplitTweet(" example "))
+
+	text := strings.Repeat("a", MaxTweetLength)
+	assert.Equal(t, []string{text}, SplitTweet(text))
+
+	url := "https://www.systemli.org/" + strings.Repeat("x", 300)
+	text = strings.Repeat("word ", 55) + url
+	tweets := SplitTweet(text)
+
+	assert.Equal(t, 2, len(tweets))
+	assert.True(t, strings.HasSuffix(tweets[0], " 1/2"))
+	assert.Equal(t, url+" 2/2", tweets[1])
+	for _, tweet := range tweets {
+		assert.True(t, TweetLength(tweet) <= MaxTweetLength)
+	}
+}
+
+func TestSplitTweetLongWord(t *testing.T) {
+	tweets := SplitTweet(strings.Repeat("a", 1000))
+
+	assert.Equal(t, 4, len(tweets))
+	assert.True(t, strings.HasSuffix(tweets[3], " 4/4"))
+	for _, tweet := range tweets {
+		assert.True(t, TweetLength(tweet) <= MaxTweetLength)
+	}
+}
+
+func TestSplitTweetManyParts(t *testing.T) {
+	tweets := SplitTweet(strings.Repeat("lorem ipsum ", 300))
+
+	assert.Equal(t, 14, len(tweets))
+	assert.True(t, strings.HasSuffix(tweets[0], " 1/14"))
+	assert.True(t, strings.HasSuffix(tweets[13], " 14/14"))
+	for _, tweet := range tweets {
+		assert.True(t, TweetLength(tweet) <= MaxTweetLength)
+	}
+}
@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	. "github.com/systemli/ticker/internal/model"
+	. "github.com/systemli/ticker/internal/storage"
+)
+
+const (
+	EventHeader     = "X-Ticker-Event"
+	SignatureHeader = "X-Ticker-Signature"
+)
+
+var (
+	//MaxAttempts is the number of tries for a delivery.
+	MaxAttempts = 5
+	//RetryDelay is the initial delay between two attempts. It doubles with every retry.
+	RetryDelay = 10 * time.Second
+	//ClaimTimeout is the time an attempt may take before the delivery is attempted again.
+	ClaimTimeout = time.Minute
+	//KeepDeliveries is the number of recorded deliveries kept for every webhook.
+	KeepDeliveries = 100
+	//Client is used for all deliveries.
+	Client = &http.Client{Timeout: 10 * time.Second}
+
+	pending sync.WaitGroup
+	//outbox guards the bookkeeping of the pending deliveries, the webhooks are called without holding it
+	outbox sync.Mutex
+)
+
+//Payload is the JSON body posted to the webhook url.
+type Payload struct {
+	Event        string      `json:"event"`
+	Ticker       int         `json:"ticker"`
+	CreationDate time.Time   `json:"creation_date"`
+	Data         interface{} `json:"data"`
+}
+
+//Dispatch sends the event to all webhooks of the ticker which subscribed to it.
+//The deliveries happen in the background.
+func Dispatch(event string, tickerID int, data interface{}) {
+	webhooks, err := FindWebhooksByTicker(tickerID)
+	if err != nil {
+		log.WithError(err).WithField("ticker", tickerID).Error("could not find webhooks")
+		return
+	}
+
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(Payload{Event: event, Ticker: tickerID, CreationDate: time.Now(), Data: data})
+			if err != nil {
+				log.WithError(err).WithField("event", event).Error("could not encode webhook payload")
+				return
+			}
+		}
+
+		// the delivery is claimed by the first attempt, it's retried when the attempt doesn't finish
+		delivery := &PendingWebhookDelivery{
+			CreationDate: time.Now(),
+			Webhook:      webhook.ID,
+			Event:        event,
+			Body:         body,
+			NextAttempt:  time.Now().Add(ClaimTimeout),
+		}
+		if err := DB.Save(delivery); err != nil {
+			log.WithError(err).WithField("webhook", webhook.ID).Error("could not save pending webhook delivery")
+			continue
+		}
+
+		pending.Add(1)
+		go func(webhook Webhook, delivery PendingWebhookDelivery) {
+			defer pending.Done()
+			attempt(webhook, &delivery)
+		}(webhook, *delivery)
+	}
+}
+
+//Wait blocks until all running attempts are finished. Failed deliveries are retried by RunOutbox.
+func Wait() {
+	pending.Wait()
+}
+
+//RetryPending attempts all pending deliveries which are due.
+func RetryPending() error {
+	deliveries, err := claimDueDeliveries()
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var webhook Webhook
+		if err := DB.One("ID", delivery.Webhook, &webhook); err != nil {
+			// the webhook was removed in the meantime
+			DB.DeleteStruct(&delivery)
+			continue
+		}
+
+		attempt(webhook, &delivery)
+	}
+
+	return nil
+}
+
+//RunOutbox retries the pending deliveries in the given interval until the context is done.
+func RunOutbox(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := RetryPending(); err != nil {
+				log.WithError(err).Error("could not retry pending webhook deliveries")
+			}
+		}
+	}
+}
+
+//Sign returns the signature for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+//claimDueDeliveries returns the due deliveries and postpones them, so concurrent runs don't send them twice.
+func claimDueDeliveries() ([]PendingWebhookDelivery, error) {
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	deliveries, err := FindDuePendingWebhookDeliveries()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deliveries {
+		deliveries[i].NextAttempt = time.Now().Add(ClaimTimeout)
+		if err := DB.Save(&deliveries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return deliveries, nil
+}
+
+//attempt posts the event to the webhook, records the attempt and schedules the next one when it failed.
+func attempt(webhook Webhook, delivery *PendingWebhookDelivery) {
+	delivery.Attempts++
+	record := NewWebhookDelivery(webhook, delivery.Event, delivery.Attempts)
+
+	code, err := post(webhook, delivery.Event, delivery.Body)
+	record.StatusCode = code
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if err := DB.Save(record); err != nil {
+		log.WithError(err).WithField("webhook", webhook.ID).Error("could not save webhook delivery")
+	}
+	if err := PruneWebhookDeliveries(webhook.ID, KeepDeliveries); err != nil {
+		log.WithError(err).WithField("webhook", webhook.ID).Error("could not prune webhook deliveries")
+	}
+
+	outbox.Lock()
+	defer outbox.Unlock()
+
+	if err == nil || delivery.Attempts >= MaxAttempts {
+		if err != nil {
+			log.WithField("webhook", webhook.ID).WithField("event", delivery.Event).Error("webhook delivery failed")
+		}
+
+		if err := DB.DeleteStruct(delivery); err != nil {
+			log.WithError(err).WithField("webhook", webhook.ID).Error("could not remove pending webhook delivery")
+		}
+		return
+	}
+
+	delivery.NextAttempt = time.Now().Add(RetryDelay * time.Duration(1<<uint(delivery.Attempts-1)))
+	if err := DB.Save(delivery); err != nil {
+		log.WithError(err).WithField("webhook", webhook.ID).Error("could not save pending webhook delivery")
+	}
+}
+
+func post(webhook Webhook, event string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	res, err := Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,167 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systemli/ticker/internal/model"
+	"github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/webhook"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", webhook.Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestDispatch(t *testing.T) {
+	setup()
+
+	var requests []*http.Request
+	var payloads []webhook.Payload
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		var payload webhook.Payload
+		json.Unmarshal(body, &payload)
+
+		requests = append(requests, r)
+		payloads = append(payloads, payload)
+		signatures = append(signatures, webhook.Sign("secret", body))
+
+		// the first attempt fails
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	subscribed := model.NewWebhook()
+	subscribed.Ticker = 1
+	subscribed.URL = server.URL
+	subscribed.Secret = "secret"
+	subscribed.Events = []string{model.EventMessageCreated}
+	storage.DB.Save(subscribed)
+
+	other := model.NewWebhook()
+	other.Ticker = 1
+	other.URL = server.URL
+	other.Events = []string{model.EventTickerReset}
+	storage.DB.Save(other)
+
+	webhook.Dispatch(model.EventMessageCreated, 1, map[string]string{"text": "message"})
+	webhook.Wait()
+
+	// the failed delivery is stored and retried by the outbox
+	assert.Equal(t, 1, len(requests))
+	assert.Nil(t, webhook.RetryPending())
+
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, model.EventMessageCreated, requests[1].Header.Get(webhook.EventHeader))
+	assert.Equal(t, signatures[1], requests[1].Header.Get(webhook.SignatureHeader))
+	assert.Equal(t, model.EventMessageCreated, payloads[1].Event)
+	assert.Equal(t, 1, payloads[1].Ticker)
+	assert.Equal(t, map[string]interface{}{"text": "message"}, payloads[1].Data)
+
+	deliveries, err := storage.FindWebhookDeliveries(subscribed.ID, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deliveries))
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, 200, deliveries[0].StatusCode)
+	assert.Empty(t, deliveries[0].Error)
+	assert.Equal(t, 1, deliveries[1].Attempt)
+	assert.Equal(t, 500, deliveries[1].StatusCode)
+	assert.Equal(t, "unexpected status code 500", deliveries[1].Error)
+
+	deliveries, err = storage.FindWebhookDeliveries(other.ID, 10)
+	assert.Nil(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestDispatchGivesUp(t *testing.T) {
+	setup()
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	wh := model.NewWebhook()
+	wh.Ticker = 1
+	wh.URL = server.URL
+	wh.Events = []string{model.EventTickerUpdated}
+	storage.DB.Save(wh)
+
+	webhook.Dispatch(model.EventTickerUpdated, 1, nil)
+	webhook.Wait()
+	for i := 0; i < webhook.MaxAttempts; i++ {
+		webhook.RetryPending()
+	}
+
+	assert.Equal(t, webhook.MaxAttempts, attempts)
+
+	deliveries, _ := storage.FindWebhookDeliveries(wh.ID, 10)
+	assert.Equal(t, webhook.MaxAttempts, len(deliveries))
+
+	var pending []model.PendingWebhookDelivery
+	storage.DB.All(&pending)
+	assert.Empty(t, pending)
+}
+
+func TestRetryPendingRemovedWebhook(t *testing.T) {
+	setup()
+
+	storage.DB.Save(&model.PendingWebhookDelivery{Webhook: 42, Event: model.EventTickerUpdated, NextAttempt: time.Now()})
+
+	assert.Nil(t, webhook.RetryPending())
+
+	var pending []model.PendingWebhookDelivery
+	storage.DB.All(&pending)
+	assert.Empty(t, pending)
+}
+
+func TestDeliveriesArePruned(t *testing.T) {
+	setup()
+	webhook.KeepDeliveries = 2
+	defer func() { webhook.KeepDeliveries = 100 }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	wh := model.NewWebhook()
+	wh.Ticker = 1
+	wh.URL = server.URL
+	wh.Events = []string{model.EventTickerUpdated}
+	storage.DB.Save(wh)
+
+	for i := 0; i < 3; i++ {
+		webhook.Dispatch(model.EventTickerUpdated, 1, nil)
+		webhook.Wait()
+	}
+
+	var deliveries []model.WebhookDelivery
+	storage.DB.All(&deliveries)
+	assert.Equal(t, 2, len(deliveries))
+	assert.Equal(t, 2, deliveries[0].ID)
+	assert.Equal(t, 3, deliveries[1].ID)
+}
+
+func setup() {
+	webhook.RetryDelay = 0
+
+	if storage.DB == nil {
+		storage.DB = storage.OpenDB("ticker_test.db")
+	}
+	storage.DB.Drop("Webhook")
+	storage.DB.Drop("WebhookDelivery")
+	storage.DB.Drop("PendingWebhookDelivery")
+}
@@ -15,9 +15,11 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	. "github.com/systemli/ticker/internal/api"
+	"github.com/systemli/ticker/internal/bot"
 	"github.com/systemli/ticker/internal/bridge"
 	. "github.com/systemli/ticker/internal/model"
 	. "github.com/systemli/ticker/internal/storage"
+	"github.com/systemli/ticker/internal/webhook"
 )
 
 var (
@@ -39,12 +41,24 @@ func main() {
 		}
 	}()
 
+	// Background workers are stopped before the server shuts down.
+	workers, stopWorkers := context.WithCancel(context.Background())
+	go bridge.RunOutbox(workers, time.Minute)
+	go webhook.RunOutbox(workers, 10*time.Second)
+	go RunScheduler(workers, 10*time.Second)
+	defer stopWorkers()
+
+	if Config.TelegramInboundEnabled() {
+		go bot.NewTelegramBot(Config.TelegramBotToken, Config.TelegramAPIURL, SubmitMessage).Run(workers)
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 5 seconds.
 	quit := make(chan os.Signal)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
 	log.Println("Shutdown Ticker")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -52,6 +66,9 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal(err)
 	}
+
+	// running webhook attempts are finished, the remaining deliveries are retried after the restart
+	webhook.Wait()
 }
 
 func init() {
@@ -62,7 +79,19 @@ func init() {
 	DB = OpenDB(Config.Database)
 
 	if Config.TwitterEnabled() {
-		bridge.Twitter = bridge.NewTwitterBridge(Config.TwitterConsumerKey, Config.TwitterConsumerSecret)
+		bridge.Register(bridge.TwitterBridgeName, bridge.NewTwitterBridge(Config.TwitterConsumerKey, Config.TwitterConsumerSecret))
+	}
+	if Config.TelegramEnabled() {
+		bridge.Register(bridge.TelegramBridgeName, bridge.NewTelegramBridge(Config.TelegramBotToken, Config.TelegramAPIURL))
+	}
+	bridge.Register(bridge.MastodonBridgeName, bridge.NewMastodonBridge())
+
+	migrated, err := MigrateTickerRoles()
+	if err != nil {
+		log.WithError(err).Fatal("could not migrate the roles of the ticker members")
+	}
+	if migrated > 0 {
+		log.WithField("users", migrated).Info("members of the existing tickers became their owners")
 	}
 
 	firstRun()
@@ -79,6 +108,10 @@ func init() {
 
 	log.SetLevel(lvl)
 
+	if Config.SecretGenerated {
+		log.Warn("no secret configured, a random secret is used: sessions end with every restart and two-factor authentication is not available")
+	}
+
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {